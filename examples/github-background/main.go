@@ -3,14 +3,16 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
 	"runtime"
-	"sync"
+	"time"
 
 	"github.com/mouuff/go-rocket-update/pkg/provider"
 	"github.com/mouuff/go-rocket-update/pkg/updater"
 )
 
-// This example shows how you can run the update in background
+// This example shows how you can check for updates periodically in background
 
 func main() {
 
@@ -23,20 +25,25 @@ func main() {
 		Version:        "v0.0.0",
 	}
 
-	var wg sync.WaitGroup
-
-	wg.Add(1)
-	go func() {
-		updateStatus, err := u.Update()
-		if err != nil {
+	scheduler := &updater.Scheduler{
+		Updater:  u,
+		Interval: time.Hour,
+		Jitter:   5 * time.Minute,
+		OnUpdated: func(version string) {
+			log.Println("Updated to " + version + ", restart to use the new version")
+		},
+		OnError: func(err error) {
 			log.Println(err)
-		}
-		if updateStatus == updater.Updated {
-			log.Println("Updated!")
-		}
+		},
+	}
+	if err := scheduler.Start(); err != nil {
+		log.Fatal(err)
+	}
+	defer scheduler.Stop()
 
-		wg.Done()
-	}()
 	log.Println(u.Version)
-	wg.Wait()
+	// your program runs here, we just wait for Ctrl+C in this example
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	<-interrupt
 }
@@ -1,3 +1,3 @@
 module github.com/mouuff/go-rocket-update
 
-go 1.12
+go 1.20
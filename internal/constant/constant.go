@@ -3,4 +3,6 @@ package constant
 const (
 	// SignatureRelPath defines the relative path to the signatures file
 	SignatureRelPath = "signatures.json"
+	// ManifestRelPath defines the relative path to the release manifest (see provider.ReleaseMetadata)
+	ManifestRelPath = "manifest.json"
 )
@@ -0,0 +1,157 @@
+package fileio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrBackupNotFound is returned when there is no backup for a version
+var ErrBackupNotFound = errors.New("backup not found")
+
+// backupIndexName is the name of the index file of a BackupStore
+const backupIndexName = "backups.json"
+
+// Backup describes a copy of a file kept in a BackupStore
+type Backup struct {
+	Version string    `json:"version"` // Version of the file
+	Name    string    `json:"name"`    // Name of the copy in the directory of the store
+	Time    time.Time `json:"time"`    // Time the backup was created
+}
+
+// BackupStore keeps copies of a file labeled with their version
+// The copies and an index (backups.json) are stored in Dir
+type BackupStore struct {
+	Dir string
+}
+
+// Path gets the path of the copy of backup
+func (s *BackupStore) Path(backup *Backup) string {
+	return filepath.Join(s.Dir, backup.Name)
+}
+
+// PathFor gets the path where Add would copy the file located at path with the label version
+func (s *BackupStore) PathFor(path string, version string) string {
+	return filepath.Join(s.Dir, backupName(version, filepath.Base(path)))
+}
+
+// List lists the backups, the newest first
+func (s *BackupStore) List() ([]Backup, error) {
+	content, err := os.ReadFile(filepath.Join(s.Dir, backupIndexName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var backups []Backup
+	if err = json.Unmarshal(content, &backups); err != nil {
+		return nil, fmt.Errorf("invalid backup index: %w", err)
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].Time.After(backups[j].Time)
+	})
+	return backups, nil
+}
+
+// Get gets the backup of version
+// returns ErrBackupNotFound if there is none
+func (s *BackupStore) Get(version string) (*Backup, error) {
+	backups, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, backup := range backups {
+		if backup.Version == version {
+			return &backup, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrBackupNotFound, version)
+}
+
+// Add copies the file located at path in the store with the label version
+// A previous backup of the same version is replaced
+func (s *BackupStore) Add(path string, version string) (*Backup, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(s.Dir, 0755); err != nil {
+		return nil, err
+	}
+	backups, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	backup := Backup{
+		Version: version,
+		Name:    backupName(version, filepath.Base(path)),
+		Time:    time.Now(),
+	}
+	if err = CopyFile(path, s.Path(&backup)); err != nil {
+		return nil, err
+	}
+	if err = os.Chmod(s.Path(&backup), info.Mode().Perm()); err != nil {
+		return nil, err
+	}
+	kept := []Backup{backup}
+	for _, b := range backups {
+		if b.Version != version {
+			kept = append(kept, b)
+		} else if b.Name != backup.Name {
+			os.Remove(s.Path(&b))
+		}
+	}
+	if err = s.writeIndex(kept); err != nil {
+		return nil, err
+	}
+	return &backup, nil
+}
+
+// Prune removes the backups older than maxAge and keeps at most maxCount backups
+// maxCount and maxAge are ignored when they are not positive
+func (s *BackupStore) Prune(maxCount int, maxAge time.Duration) error {
+	backups, err := s.List()
+	if err != nil {
+		return err
+	}
+	var kept []Backup
+	for i, backup := range backups {
+		if (maxCount > 0 && i >= maxCount) || (maxAge > 0 && time.Since(backup.Time) > maxAge) {
+			if err = os.Remove(s.Path(&backup)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		kept = append(kept, backup)
+	}
+	if len(kept) == len(backups) {
+		return nil
+	}
+	return s.writeIndex(kept)
+}
+
+// writeIndex replaces the index of the store
+func (s *BackupStore) writeIndex(backups []Backup) error {
+	content, err := json.MarshalIndent(backups, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(filepath.Join(s.Dir, backupIndexName), content, 0644)
+}
+
+// backupName gets the file name of a backup, example: "v1.0.0_myapp.exe"
+func backupName(version string, name string) string {
+	safeVersion := strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || strings.ContainsRune(".+-", r) {
+			return r
+		}
+		return '_'
+	}, version)
+	return safeVersion + "_" + name
+}
@@ -0,0 +1,91 @@
+package fileio_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+)
+
+func TestBackupStore(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	executable := filepath.Join(tmpDir, "myapp")
+	store := &fileio.BackupStore{Dir: filepath.Join(tmpDir, "backups")}
+
+	backups, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 0 {
+		t.Fatal("A new store should be empty")
+	}
+
+	for _, version := range []string{"v1.0.0", "v1.1.0", "v1.2.0", "v1.1.0"} {
+		if err = os.WriteFile(executable, []byte(version), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = store.Add(executable, version); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	backups, err = store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 3 {
+		t.Fatalf("The backup of a version should be replaced, got %d backups", len(backups))
+	}
+	if backups[0].Version != "v1.1.0" || backups[1].Version != "v1.2.0" || backups[2].Version != "v1.0.0" {
+		t.Errorf("The newest backups should be listed first: %+v", backups)
+	}
+
+	backup, err := store.Get("v1.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(store.Path(backup))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "v1.2.0" {
+		t.Errorf("Bad backup content: %s", content)
+	}
+	if _, err = store.Get("v0.1.0"); !errors.Is(err, fileio.ErrBackupNotFound) {
+		t.Error("Get should return ErrBackupNotFound")
+	}
+
+	if err = store.Prune(2, 0); err != nil {
+		t.Fatal(err)
+	}
+	backups, err = store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("Prune should keep 2 backups, got %d", len(backups))
+	}
+	if fileio.FileExists(filepath.Join(store.Dir, "v1.0.0_myapp")) {
+		t.Error("The copy of a pruned backup should be removed")
+	}
+
+	if err = store.Prune(0, time.Nanosecond); err != nil {
+		t.Fatal(err)
+	}
+	backups, err = store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 0 {
+		t.Errorf("Prune should remove the old backups, got %d", len(backups))
+	}
+}
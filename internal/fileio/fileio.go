@@ -70,6 +70,28 @@ func CompareFiles(fileA, fileB string) (bool, error) {
 	return true, nil
 }
 
+// WriteFileAtomic writes content to a temporary file and renames it to path
+// so path is never partially written
+func WriteFileAtomic(path string, content []byte, perm os.FileMode) error {
+	tmpPath := path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(content)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
 // TempDir creates a new temporary directory
 func TempDir() (string, error) {
 	return os.MkdirTemp("", "rocket-updater")
@@ -0,0 +1,94 @@
+package fileio
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Steps of a patch saved in the journal
+const (
+	journalStarted  = "started"  // the new file is being written to a temporary file
+	journalPrepared = "prepared" // the temporary file is complete and is being renamed to the destination
+)
+
+// patchJournal describes the patch in progress
+type patchJournal struct {
+	State           string `json:"state"`
+	DestinationPath string `json:"destination_path"`
+	BackupPath      string `json:"backup_path"`
+	TempPath        string `json:"temp_path,omitempty"`
+}
+
+// writeJournal saves the step of the patch in progress (does nothing without JournalPath)
+func (p *Patcher) writeJournal(journal *patchJournal) error {
+	if p.JournalPath == "" {
+		return nil
+	}
+	content, err := json.Marshal(journal)
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(p.JournalPath, content, 0644)
+}
+
+// removeJournal removes the journal once the patch is done
+func (p *Patcher) removeJournal() error {
+	if p.JournalPath == "" {
+		return nil
+	}
+	if err := os.Remove(p.JournalPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Recover completes or reverts a patch interrupted by a crash using the journal at JournalPath
+// The destination is restored from the temporary file (if it was complete) or from the backup
+// if it is missing, then the temporary files and the journal are removed.
+// It does nothing if there is no journal
+func (p *Patcher) Recover() error {
+	if p.JournalPath == "" {
+		return nil
+	}
+	content, err := os.ReadFile(p.JournalPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	journal := &patchJournal{}
+	if err = json.Unmarshal(content, journal); err != nil {
+		// The journal is written atomically, so this is not an interrupted patch
+		return fmt.Errorf("invalid patch journal %s: %w", p.JournalPath, err)
+	}
+
+	p.logger().Warn("recovering interrupted patch", "path", journal.DestinationPath, "state", journal.State)
+	if !FileExists(journal.DestinationPath) {
+		if journal.State == journalPrepared && journal.TempPath != "" && FileExists(journal.TempPath) {
+			p.logger().Info("completing interrupted patch", "path", journal.DestinationPath)
+			err = os.Rename(journal.TempPath, journal.DestinationPath)
+		} else if FileExists(journal.BackupPath) {
+			p.logger().Info("reverting interrupted patch", "path", journal.DestinationPath, "backup", journal.BackupPath)
+			err = os.Rename(journal.BackupPath, journal.DestinationPath)
+		} else {
+			err = fmt.Errorf("%s and its backup %s are missing", journal.DestinationPath, journal.BackupPath)
+		}
+		if err != nil {
+			return fmt.Errorf("could not recover patch: %w", err)
+		}
+	}
+
+	tmpPaths, err := filepath.Glob(tempPattern(journal.DestinationPath) + "*")
+	if err != nil {
+		return err
+	}
+	for _, tmpPath := range tmpPaths {
+		if err = os.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return p.removeJournal()
+}
@@ -0,0 +1,105 @@
+package fileio_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+)
+
+func TestPatcherRecover(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	destinationPath := filepath.Join(tmpDir, "executable")
+	backupPath := destinationPath + ".old"
+	tempPath := filepath.Join(tmpDir, ".executable.new123")
+	patcher := &fileio.Patcher{
+		SourcePath:      filepath.Join(tmpDir, "source"),
+		DestinationPath: destinationPath,
+		BackupPath:      backupPath,
+		JournalPath:     destinationPath + ".journal",
+	}
+	if err = patcher.Recover(); err != nil {
+		t.Fatal("Recover() should do nothing without journal: ", err)
+	}
+
+	tests := []struct {
+		state       string
+		destination bool
+		temp        bool
+		backup      bool
+		expected    string
+	}{
+		{"started", true, true, false, "old"},       // crashed while writing the new file
+		{"prepared", true, true, true, "old"},       // crashed before the rename
+		{"prepared", true, false, true, "new"},      // crashed after the rename
+		{"prepared", false, true, true, "new"},      // crashed between the two renames (windows)
+		{"prepared", false, false, true, "old"},     // the new file is lost
+		{"started", false, true, true, "old"},       // the new file is not complete
+		{"prepared", false, false, false, "broken"}, // nothing can be recovered
+	}
+	for i, test := range tests {
+		os.Remove(destinationPath)
+		os.Remove(backupPath)
+		os.Remove(tempPath)
+		if test.destination {
+			content := "old"
+			if test.expected == "new" {
+				content = "new"
+			}
+			os.WriteFile(destinationPath, []byte(content), 0755)
+		}
+		if test.temp {
+			os.WriteFile(tempPath, []byte("new"), 0755)
+		}
+		if test.backup {
+			os.WriteFile(backupPath, []byte("old"), 0755)
+		}
+		journal := fmt.Sprintf(`{"state": %q, "destination_path": %q, "backup_path": %q, "temp_path": %q}`,
+			test.state, destinationPath, backupPath, tempPath)
+		if err = os.WriteFile(patcher.JournalPath, []byte(journal), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		err = patcher.Recover()
+		if test.expected == "broken" {
+			if err == nil {
+				t.Errorf("test %d: Recover() should fail when the destination can't be restored", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		content, err := os.ReadFile(destinationPath)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if string(content) != test.expected {
+			t.Errorf("test %d: recovered %s, expected %s", i, content, test.expected)
+		}
+		if fileio.FileExists(tempPath) {
+			t.Errorf("test %d: the temporary file should be removed", i)
+		}
+		if fileio.FileExists(patcher.JournalPath) {
+			t.Errorf("test %d: the journal should be removed", i)
+		}
+	}
+
+	// A successful Apply leaves no journal
+	os.Remove(patcher.JournalPath)
+	os.WriteFile(destinationPath, []byte("old"), 0755)
+	os.WriteFile(patcher.SourcePath, []byte("new"), 0755)
+	if err = patcher.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if fileio.FileExists(patcher.JournalPath) {
+		t.Error("The journal should be removed after Apply()")
+	}
+}
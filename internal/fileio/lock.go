@@ -0,0 +1,44 @@
+package fileio
+
+import (
+	"os"
+)
+
+// FileLock is an advisory lock shared between processes (flock on unix, LockFileEx on windows)
+// The file at Path is created if needed and is never removed
+type FileLock struct {
+	Path string
+	file *os.File
+}
+
+// TryLock tries to acquire the lock without waiting
+// returns false if the lock is held by another process (or another FileLock)
+func (l *FileLock) TryLock() (bool, error) {
+	if l.file != nil {
+		return true, nil
+	}
+	file, err := os.OpenFile(l.Path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return false, err
+	}
+	locked, err := lockFile(file)
+	if err != nil || !locked {
+		file.Close()
+		return false, err
+	}
+	l.file = file
+	return true, nil
+}
+
+// Unlock releases the lock
+func (l *FileLock) Unlock() error {
+	if l.file == nil {
+		return nil
+	}
+	err := unlockFile(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	return err
+}
@@ -0,0 +1,18 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package fileio
+
+import (
+	"os"
+)
+
+// lockFile always succeeds: file locks are not supported on this platform
+func lockFile(file *os.File) (bool, error) {
+	return true, nil
+}
+
+// unlockFile does nothing: file locks are not supported on this platform
+func unlockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,57 @@
+package fileio_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+)
+
+func TestFileLock(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "update.lock")
+	lockA := &fileio.FileLock{Path: path}
+	lockB := &fileio.FileLock{Path: path}
+
+	locked, err := lockA.TryLock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !locked {
+		t.Fatal("The first lock should be acquired")
+	}
+	locked, err = lockB.TryLock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locked {
+		t.Fatal("The lock should not be acquired twice")
+	}
+	if err = lockA.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	locked, err = lockB.TryLock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !locked {
+		t.Fatal("The lock should be acquired once released")
+	}
+	if err = lockB.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err = lockB.Unlock(); err != nil {
+		t.Fatal("Unlocking twice should not cause problem: ", err)
+	}
+
+	badLock := &fileio.FileLock{Path: filepath.Join(tmpDir, "doesnotexist", "update.lock")}
+	if _, err = badLock.TryLock(); err == nil {
+		t.Error("TryLock() should fail when the file can't be created")
+	}
+}
@@ -1,7 +1,10 @@
 package fileio
 
 import (
+	"errors"
+	"io"
 	"os"
+	"path/filepath"
 )
 
 // Patcher is used to replace file located at DestinationPath with the
@@ -10,32 +13,131 @@ type Patcher struct {
 	SourcePath      string
 	DestinationPath string
 	BackupPath      string      // Stores the backup of DestinationPath before replacement (must be on the same filesytem as DestinationPath)
-	Mode            os.FileMode // Mode used to create the new file at DestinationPath
+	Mode            os.FileMode // (optional) Mode of the new file at DestinationPath, the mode of DestinationPath is kept by default
+	JournalPath     string      // (optional) Saves the steps of Apply so an interrupted patch can be recovered (see Recover)
+	Logger          Logger      // (optional) Records the steps of the patch
 }
 
-// Apply replaces the file located at DestinationPath with the one located at BackupPath and
-// then new file is created at DestinationPath with the content of SourcePath
-func (p *Patcher) Apply() error {
-	content, err := os.ReadFile(p.SourcePath)
-	if err != nil {
+// logger gets the Logger of the patcher
+func (p *Patcher) logger() Logger {
+	if p.Logger == nil {
+		return nopLogger{}
+	}
+	return p.Logger
+}
+
+// tempPattern gets the prefix of the temporary files created next to destination
+func tempPattern(destination string) string {
+	return filepath.Join(filepath.Dir(destination), "."+filepath.Base(destination)+".new")
+}
+
+// Apply backs up the file located at DestinationPath at BackupPath and
+// then replaces it with the content of SourcePath
+// The new file is written next to DestinationPath with the same owner, mode and extended attributes,
+// then it is atomically renamed to DestinationPath (except on windows where the file is moved to BackupPath first)
+// If JournalPath is set, a previous interrupted patch is recovered first
+func (p *Patcher) Apply() (err error) {
+	if err = p.Recover(); err != nil {
 		return err
 	}
-	_ = p.CleanUp() // We dont check error on purpose
-	err = os.Rename(p.DestinationPath, p.BackupPath)
+	info, err := os.Stat(p.DestinationPath)
 	if err != nil {
 		return err
 	}
-	err = os.WriteFile(p.DestinationPath, content, p.Mode)
+	journal := &patchJournal{
+		State:           journalStarted,
+		DestinationPath: p.DestinationPath,
+		BackupPath:      p.BackupPath,
+	}
+	if err = p.writeJournal(journal); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			p.removeJournal()
+		}
+	}()
+	tmpPath, err := p.writeTemp(info)
 	if err != nil {
-		p.Rollback()
+		p.logger().Error("could not write the new file", "path", p.DestinationPath, "error", err)
 		return err
 	}
-	return nil
+	p.logger().Debug("new file written", "path", tmpPath, "source", p.SourcePath)
+	defer func() {
+		if err != nil {
+			os.Remove(tmpPath)
+		}
+	}()
+	journal.State = journalPrepared
+	journal.TempPath = tmpPath
+	if err = p.writeJournal(journal); err != nil {
+		return err
+	}
+	_ = p.CleanUp() // We dont check error on purpose
+	if err = replaceFile(tmpPath, p.DestinationPath, p.BackupPath, info); err != nil {
+		p.logger().Error("could not replace the file", "path", p.DestinationPath, "error", err)
+		return err
+	}
+	p.logger().Info("file replaced", "path", p.DestinationPath, "backup", p.BackupPath)
+	return p.removeJournal()
+}
+
+// writeTemp streams the content of SourcePath to a temporary file in the directory of DestinationPath
+// the temporary file gets the metadata of the destination (info) and is synced to the disk
+func (p *Patcher) writeTemp(info os.FileInfo) (tmpPath string, err error) {
+	in, err := os.Open(p.SourcePath)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	out, err := os.CreateTemp(filepath.Dir(p.DestinationPath), filepath.Base(tempPattern(p.DestinationPath))+"*")
+	if err != nil {
+		return "", err
+	}
+	tmpPath = out.Name()
+	defer func() {
+		if err != nil {
+			out.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+	if _, err = io.Copy(out, in); err != nil {
+		return
+	}
+	mode := p.Mode
+	if mode == 0 {
+		mode = info.Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+	}
+	// The owner must be changed first: chown clears the setuid bits and the file capabilities
+	// Keeping the owner is best effort: an unprivileged user can't give the file to another user
+	if err = copyOwner(info, out); errors.Is(err, os.ErrPermission) {
+		p.logger().Warn("could not keep the owner of the file", "path", p.DestinationPath, "error", err)
+		err = nil
+	} else if err != nil {
+		return
+	}
+	if err = out.Chmod(mode); err != nil {
+		return
+	}
+	if err = copyXattrs(p.DestinationPath, tmpPath); err != nil {
+		return
+	}
+	if err = out.Sync(); err != nil {
+		return
+	}
+	err = out.Close()
+	return
 }
 
 // Rollback replaces the file located at BackupPath with the one located at DestinationPath.
 func (p *Patcher) Rollback() error {
-	return os.Rename(p.BackupPath, p.DestinationPath)
+	if err := os.Rename(p.BackupPath, p.DestinationPath); err != nil {
+		p.logger().Error("could not rollback", "path", p.DestinationPath, "error", err)
+		return err
+	}
+	p.logger().Info("file rolled back", "path", p.DestinationPath, "backup", p.BackupPath)
+	return nil
 }
 
 // CleanUp cleans up backup file
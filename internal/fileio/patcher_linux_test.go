@@ -0,0 +1,51 @@
+package fileio_test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+)
+
+func TestPatcherKeepsXattrs(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	sourcePath := filepath.Join(tmpDir, "source")
+	destinationPath := filepath.Join(tmpDir, "executable")
+	if err = os.WriteFile(sourcePath, []byte("new"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(destinationPath, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	err = syscall.Setxattr(destinationPath, "user.rocket-update", []byte("value"), 0)
+	if err == syscall.ENOTSUP {
+		t.Skip("extended attributes are not supported by the filesystem")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patcher := &fileio.Patcher{
+		SourcePath:      sourcePath,
+		DestinationPath: destinationPath,
+		BackupPath:      destinationPath + ".old",
+	}
+	if err = patcher.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 64)
+	size, err := syscall.Getxattr(destinationPath, "user.rocket-update", buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf[:size]) != "value" {
+		t.Errorf("Bad extended attribute: %s", buf[:size])
+	}
+}
@@ -0,0 +1,27 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !illumos && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!illumos,!linux,!netbsd,!openbsd,!solaris
+
+package fileio
+
+import (
+	"os"
+)
+
+// copyOwner does nothing, the owner of the new file is the current user
+func copyOwner(info os.FileInfo, file *os.File) error {
+	return nil
+}
+
+// replaceFile moves dest to backup and renames src to dest
+// A running executable can't be replaced on windows but it can be moved,
+// so dest is missing for a short time and restored on failure
+func replaceFile(src, dest, backup string, info os.FileInfo) error {
+	if err := os.Rename(dest, backup); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dest); err != nil {
+		os.Rename(backup, dest)
+		return err
+	}
+	return nil
+}
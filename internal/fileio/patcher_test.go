@@ -1,7 +1,9 @@
 package fileio_test
 
 import (
+	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/mouuff/go-rocket-update/internal/fileio"
@@ -135,3 +137,93 @@ func TestPatcher(t *testing.T) {
 		t.Error("Apply() should not work with unknown BackupPath")
 	}
 }
+
+func TestPatcherKeepsMetadata(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	sourcePath := filepath.Join(tmpDir, "source")
+	destinationDir := filepath.Join(tmpDir, "bin")
+	destinationPath := filepath.Join(destinationDir, "executable")
+	backupPath := destinationPath + ".old"
+	if err = os.Mkdir(destinationDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(sourcePath, []byte("new"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(destinationPath, []byte("old"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chmod(destinationPath, 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	patcher := &fileio.Patcher{
+		SourcePath:      sourcePath,
+		DestinationPath: destinationPath,
+		BackupPath:      backupPath,
+	}
+	if err = patcher.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(destinationPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "new" {
+		t.Errorf("Bad content: %s", content)
+	}
+	content, err = os.ReadFile(backupPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "old" {
+		t.Errorf("Bad backup content: %s", content)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(destinationPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0750 {
+			t.Errorf("The mode should be kept, got %v", info.Mode())
+		}
+	}
+	entries, err := os.ReadDir(destinationDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Temporary files should not be left, got %d files", len(entries))
+	}
+
+	patcher.Mode = 0700
+	if err = patcher.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(destinationPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0700 {
+			t.Errorf("Mode should be used when it is set, got %v", info.Mode())
+		}
+	}
+
+	patcher.SourcePath = filepath.Join(tmpDir, "doesnotexist")
+	if err = patcher.Apply(); err == nil {
+		t.Fatal("Apply() should not work with unknown SourcePath")
+	}
+	content, err = os.ReadFile(destinationPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "new" {
+		t.Error("The destination should not change when Apply() fails")
+	}
+}
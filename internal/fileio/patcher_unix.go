@@ -0,0 +1,59 @@
+//go:build aix || darwin || dragonfly || freebsd || illumos || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd illumos linux netbsd openbsd solaris
+
+package fileio
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// copyOwner gives to file the owner and the group described by info
+func copyOwner(info os.FileInfo, file *os.File) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	current, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if currentStat, ok := current.Sys().(*syscall.Stat_t); ok &&
+		currentStat.Uid == stat.Uid && currentStat.Gid == stat.Gid {
+		return nil
+	}
+	return file.Chown(int(stat.Uid), int(stat.Gid))
+}
+
+// replaceFile backs up dest at backup (using a hard link when possible) and renames src to dest
+// dest is never missing: the rename is atomic
+func replaceFile(src, dest, backup string, info os.FileInfo) error {
+	if err := os.Link(dest, backup); err != nil {
+		if err = CopyFile(dest, backup); err != nil {
+			os.Remove(backup)
+			return err
+		}
+		if err = os.Chmod(backup, info.Mode()); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(src, dest); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(dest))
+}
+
+// syncDir flushes the entries of the directory to the disk
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	if err = d.Sync(); err != nil && !errors.Is(err, syscall.EINVAL) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package fileio
+
+import (
+	"bytes"
+	"fmt"
+	"syscall"
+)
+
+// copyXattrs copies the extended attributes of src to dest (including the file capabilities)
+// they are ignored if the filesystem does not support them
+func copyXattrs(src, dest string) error {
+	names, err := xattrGet(func(buf []byte) (int, error) {
+		return syscall.Listxattr(src, buf)
+	})
+	if err == syscall.ENOTSUP || err == syscall.ENODATA {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not list extended attributes: %w", err)
+	}
+	for _, name := range bytes.Split(names, []byte{0}) {
+		if len(name) == 0 {
+			continue
+		}
+		attr := string(name)
+		value, err := xattrGet(func(buf []byte) (int, error) {
+			return syscall.Getxattr(src, attr, buf)
+		})
+		if err == syscall.ENODATA {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not get extended attribute %s: %w", attr, err)
+		}
+		if err = syscall.Setxattr(dest, attr, value, 0); err != nil && err != syscall.ENOTSUP {
+			return fmt.Errorf("could not set extended attribute %s: %w", attr, err)
+		}
+	}
+	return nil
+}
+
+// xattrGet calls get with a buffer big enough for the result
+func xattrGet(get func(buf []byte) (int, error)) ([]byte, error) {
+	for {
+		size, err := get(nil)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size)
+		size, err = get(buf)
+		if err == syscall.ERANGE {
+			continue // the value grew in the meantime
+		}
+		if err != nil {
+			return nil, err
+		}
+		return buf[:size], nil
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+// +build !linux
+
+package fileio
+
+// copyXattrs does nothing, extended attributes are only copied on linux
+func copyXattrs(src, dest string) error {
+	return nil
+}
@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"strings"
+
+	"github.com/mouuff/go-rocket-update/pkg/version"
+)
+
+// Channel is an update channel, it defines which prereleases are provided
+type Channel string
+
+const (
+	// ChannelStable only provides stable releases (this is the default channel)
+	ChannelStable Channel = "stable"
+	// ChannelBeta provides stable releases, betas and release candidates
+	ChannelBeta Channel = "beta"
+	// ChannelAlpha provides every release
+	ChannelAlpha Channel = "alpha"
+)
+
+// channelLevel gets the stability level of a channel (0 being the most stable)
+// an unknown channel is considered stable
+func channelLevel(c Channel) int {
+	switch Channel(strings.ToLower(string(c))) {
+	case ChannelBeta:
+		return 1
+	case ChannelAlpha:
+		return 2
+	}
+	return 0
+}
+
+// releaseChannel finds the most stable channel of a release using the semver prerelease
+// identifiers of its tag: "-beta" and "-rc" releases are betas, any other prerelease is an alpha.
+// A release without prerelease identifiers is a beta if it is flagged as a prerelease.
+func releaseChannel(tag string, prerelease bool) Channel {
+	v, err := version.ParseSemver(tag)
+	if err == nil && len(v.Prerelease) > 0 {
+		identifier := strings.ToLower(v.Prerelease[0])
+		if strings.HasPrefix(identifier, "beta") || strings.HasPrefix(identifier, "rc") {
+			return ChannelBeta
+		}
+		return ChannelAlpha
+	}
+	if prerelease {
+		return ChannelBeta
+	}
+	return ChannelStable
+}
+
+// Accepts checks if a release is provided by the channel
+// tag is the version of the release and prerelease tells if it is flagged as a prerelease
+func (c Channel) Accepts(tag string, prerelease bool) bool {
+	return channelLevel(releaseChannel(tag, prerelease)) <= channelLevel(c)
+}
@@ -0,0 +1,37 @@
+package provider_test
+
+import (
+	"testing"
+
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+)
+
+func TestChannelAccepts(t *testing.T) {
+	tests := []struct {
+		channel    provider.Channel
+		tag        string
+		prerelease bool
+		expected   bool
+	}{
+		{"", "v1.0.0", false, true},
+		{"", "v1.0.0-rc.1", false, false},
+		{"", "v1.0.0", true, false},
+		{provider.ChannelStable, "latest", false, true},
+		{provider.ChannelStable, "v1.0.0-beta", false, false},
+		{provider.ChannelBeta, "v1.0.0", false, true},
+		{provider.ChannelBeta, "v1.0.0", true, true},
+		{provider.ChannelBeta, "v1.0.0-beta.2", true, true},
+		{provider.ChannelBeta, "v1.0.0-RC1", false, true},
+		{provider.ChannelBeta, "v1.0.0-alpha.1", true, false},
+		{provider.ChannelBeta, "v1.0.0-dev", false, false},
+		{provider.ChannelAlpha, "v1.0.0-alpha.1", true, true},
+		{provider.ChannelAlpha, "v1.0.0-nightly", false, true},
+		{provider.ChannelAlpha, "v1.0.0", false, true},
+		{"Beta", "v1.0.0-rc.1", false, true},
+	}
+	for _, test := range tests {
+		if test.channel.Accepts(test.tag, test.prerelease) != test.expected {
+			t.Errorf("Channel(%q).Accepts(%q, %v) should be %v", test.channel, test.tag, test.prerelease, test.expected)
+		}
+	}
+}
@@ -1,44 +1,238 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
-	"regexp"
+	"strings"
 	"time"
+
+	"github.com/mouuff/go-rocket-update/pkg/version"
 )
 
+// archiveExtensions lists the extensions which are removed from a file name before looking for a version
+var archiveExtensions = []string{".tar.gz", ".tgz", ".zip", ".exe"}
+
+// trimArchiveExtension removes the archive extension of a file name
+// so "binaries-v1.0.0-rc.1.zip" is not confused with the prerelease "rc.1.zip"
+func trimArchiveExtension(name string) string {
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(name, ext) {
+			return strings.TrimSuffix(name, ext)
+		}
+	}
+	return name
+}
+
 // GetLatestVersionFromPath finds the latest version from a path
 // This is used by provider zip and gzip
 // Example: /example/binaries-v1.4.53.zip is going to match "v1.4.53"
 func GetLatestVersionFromPath(path string) (string, error) {
-	// TODO unittest
-	versionRegex := regexp.MustCompile(`v[0-9]+\.[0-9]+\.[0-9]+`)
-	version := string(versionRegex.Find([]byte(filepath.Base(path))))
-	if version == "" {
+	return GetLatestVersionFromPathWithComparer(path, nil)
+}
+
+// GetLatestVersionFromPathWithComparer is the same as GetLatestVersionFromPath but uses
+// the versioning scheme of comparer (semver if nil)
+// Example with version.CalverComparer: /example/binaries-2026.10.3.zip is going to match "2026.10.3"
+func GetLatestVersionFromPathWithComparer(path string, comparer version.Comparer) (string, error) {
+	comparer = version.OrDefault(comparer)
+	v := comparer.Find(trimArchiveExtension(filepath.Base(path)))
+	if v == "" {
 		return "", ErrProviderUnavailable
 	}
-	return version, nil
+	return v, nil
 }
 
-// GlobNewestFile same as filepath.Glob but returns only one file with the latest modification time
+// isArchiveName checks if Decompress can open the file name
+func isArchiveName(name string) bool {
+	return strings.HasSuffix(name, ".zip") || strings.HasSuffix(name, ".tar.gz")
+}
+
+// findNewestVersionName finds the name with the newest version among the names matching pattern
+// and accepted by the version filter of ctx, the names without version are ignored
+// The version.Comparer of ctx is used if comparer is nil
+// Every archive (zip or tar.gz) matches an empty pattern
+func findNewestVersionName(ctx context.Context, names []string, pattern string, comparer version.Comparer) (newestName string, newestVersion string, err error) {
+	comparer = comparerOrContext(ctx, comparer)
+	for _, name := range names {
+		match := isArchiveName(name)
+		if pattern != "" {
+			if match, err = MatchName(pattern, name); err != nil {
+				return "", "", err
+			}
+		}
+		if !match {
+			continue
+		}
+		nameVersion, err := GetLatestVersionFromPathWithComparer(name, comparer)
+		if err != nil || !AcceptsVersion(ctx, nameVersion) {
+			continue
+		}
+		if newestName != "" {
+			if cmp, err := comparer.Compare(nameVersion, newestVersion); err != nil || cmp <= 0 {
+				continue
+			}
+		}
+		newestName = name
+		newestVersion = nameVersion
+	}
+	if newestName == "" {
+		return "", "", fmt.Errorf("%w: no archive with a version matching %q", ErrFileNotFound, pattern)
+	}
+	return newestName, newestVersion, nil
+}
+
+// GlobNewestFile same as filepath.Glob but returns only one file: the one with the latest version in its name
+// Files with the same version (or without version) are ordered by modification time
 func GlobNewestFile(pattern string) (string, error) {
+	return GlobNewestFileWithComparer(pattern, nil)
+}
+
+// GlobNewestFileWithComparer is the same as GlobNewestFile but uses
+// the versioning scheme of comparer (semver if nil)
+func GlobNewestFileWithComparer(pattern string, comparer version.Comparer) (string, error) {
+	comparer = version.OrDefault(comparer)
 	matches, err := filepath.Glob(pattern)
 	if err != nil {
 		return "", err
 	}
 	var newestTime time.Time
 	var newestFile string
+	var newestVersion string
 	for _, match := range matches {
 		file, err := os.Stat(match)
-		if err == nil {
-			if newestFile == "" || newestTime.Before(file.ModTime()) {
-				newestFile = match
-				newestTime = file.ModTime()
+		if err != nil {
+			continue
+		}
+		fileVersion, _ := GetLatestVersionFromPathWithComparer(match, comparer)
+		if newestFile != "" {
+			cmp := 0
+			if fileVersion != "" && newestVersion != "" {
+				cmp, _ = comparer.Compare(fileVersion, newestVersion)
+			} else if fileVersion != "" {
+				cmp = 1
+			} else if newestVersion != "" {
+				cmp = -1
+			}
+			if cmp < 0 || (cmp == 0 && !newestTime.Before(file.ModTime())) {
+				continue
 			}
 		}
+		newestFile = match
+		newestTime = file.ModTime()
+		newestVersion = fileVersion
 	}
 	if newestFile == "" {
 		return "", ErrFileNotFound
 	}
 	return newestFile, nil
 }
+
+// httpGet sends a GET request which is cancelled when ctx is done
+func httpGet(ctx context.Context, url string) (*http.Response, error) {
+	return httpGetWithHeader(ctx, url, nil)
+}
+
+// httpGetWithHeader is the same as httpGet but the request has the headers of header
+func httpGetWithHeader(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	logger := LoggerFromContext(ctx)
+	logger.Debug("http request", "url", url)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		logger.Warn("http request failed", "url", url, "error", err)
+		return nil, err
+	}
+	logger.Debug("http response", "url", url, "status", resp.StatusCode)
+	return resp, nil
+}
+
+// checkStatus checks that the request of resp succeeded (ErrProviderUnavailable otherwise)
+func checkStatus(resp *http.Response, url string) error {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: %s returned %s", ErrProviderUnavailable, url, resp.Status)
+	}
+	return nil
+}
+
+// getJSONPage gets the JSON document at url (a page of a list) and decodes it in v
+// the request has the headers of header, found is false if the document does not exist
+// next is the URL of the next page given by the Link header (empty on the last page)
+func getJSONPage(ctx context.Context, url string, header http.Header, v interface{}) (found bool, next string, err error) {
+	resp, err := httpGetWithHeader(ctx, url, header)
+	if err != nil {
+		return false, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return false, "", nil
+	}
+	if err = checkStatus(resp, url); err != nil {
+		return false, "", err
+	}
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return false, "", fmt.Errorf("invalid response from %s: %w", url, err)
+	}
+	return true, nextPageURL(resp.Header.Get("Link")), nil
+}
+
+// nextPageURL gets the URL of the next page from a Link header
+// example: <https://api.github.com/repositories/1/releases?page=2>; rel="next", <...>; rel="last"
+func nextPageURL(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		fields := strings.Split(part, ";")
+		target := strings.TrimSpace(fields[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, param := range fields[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+			}
+		}
+	}
+	return ""
+}
+
+// downloadFile downloads the file located at url to path
+// the progress is reported with StageDownload
+func downloadFile(ctx context.Context, url string, path string) error {
+	return downloadFileWithHeader(ctx, url, nil, path)
+}
+
+// downloadFileWithHeader is the same as downloadFile but the request has the headers of header
+func downloadFileWithHeader(ctx context.Context, url string, header http.Header, path string) error {
+	resp, err := httpGetWithHeader(ctx, url, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err = checkStatus(resp, url); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	progress := Progress{Stage: StageDownload, Path: url, Total: resp.ContentLength}
+	ReportProgress(ctx, progress)
+	size, err := io.Copy(file, &progressReader{ctx: ctx, reader: resp.Body, progress: progress})
+	closeErr := file.Close()
+	if err != nil {
+		LoggerFromContext(ctx).Warn("download failed", "url", url, "error", err)
+		return err
+	}
+	LoggerFromContext(ctx).Info("downloaded", "url", url, "path", path, "size", size)
+	return closeErr
+}
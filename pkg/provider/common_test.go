@@ -6,7 +6,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mouuff/go-rocket-update/internal/fileio"
 	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+	"github.com/mouuff/go-rocket-update/pkg/version"
 )
 
 func TestGetLatestVersionFromPath(t *testing.T) {
@@ -22,6 +24,45 @@ func TestGetLatestVersionFromPath(t *testing.T) {
 	if err == nil {
 		t.Error("Should return an error")
 	}
+
+	version, err = provider.GetLatestVersionFromPath(filepath.Join("v9.9.9", "binaries-v1.2.3-rc.1.tar.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "v1.2.3-rc.1" {
+		t.Error("version != 'v1.2.3-rc.1'")
+	}
+
+	version, err = provider.GetLatestVersionFromPath("binaries-v1.0.0-linux-amd64.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "v1.0.0" {
+		t.Error("version != 'v1.0.0'")
+	}
+}
+
+func TestGetLatestVersionFromPathWithComparer(t *testing.T) {
+	v, err := provider.GetLatestVersionFromPathWithComparer("binaries_amd64-2026.10.3.zip", version.CalverComparer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "2026.10.3" {
+		t.Error("v != '2026.10.3'")
+	}
+
+	v, err = provider.GetLatestVersionFromPathWithComparer("binaries_amd64-1234.zip", version.NumericComparer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "1234" {
+		t.Error("v != '1234'")
+	}
+
+	_, err = provider.GetLatestVersionFromPathWithComparer("binaries-v1.2.3.zip", version.NumericComparer{})
+	if err == nil {
+		t.Error("Should return an error")
+	}
 }
 
 func TestGlobNewestFile(t *testing.T) {
@@ -45,3 +86,40 @@ func TestGlobNewestFile(t *testing.T) {
 		t.Error("Should return an error if file doesn't exists")
 	}
 }
+
+func TestGlobNewestFileWithComparer(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files := []string{"binaries-9.zip", "binaries-10.zip", "binaries.zip"}
+	for i, file := range files {
+		path := filepath.Join(tmpDir, file)
+		if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+		// the file with the highest version is the oldest one
+		modTime := time.Now().Add(time.Duration(i) * time.Hour)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	match, err := provider.GlobNewestFileWithComparer(filepath.Join(tmpDir, "binaries*.zip"), version.NumericComparer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(match) != "binaries-10.zip" {
+		t.Error("Expected binaries-10.zip, got " + match)
+	}
+
+	match, err = provider.GlobNewestFileWithComparer(filepath.Join(tmpDir, "binaries*.zip"), version.CalverComparer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(match) != "binaries.zip" {
+		t.Error("Expected the most recent file when there are no versions, got " + match)
+	}
+}
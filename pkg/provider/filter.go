@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/mouuff/go-rocket-update/pkg/version"
+)
+
+// VersionFilter decides if a version can be installed
+type VersionFilter func(version string) bool
+
+// filterKey is the key of the VersionFilter in a context
+type filterKey struct{}
+
+// comparerKey is the key of the version.Comparer in a context
+type comparerKey struct{}
+
+// ContextWithVersionFilter returns a copy of ctx which carries filter
+// The providers listing several releases (Github, Gitlab, Gitea) return the newest release accepted by filter
+func ContextWithVersionFilter(ctx context.Context, filter VersionFilter) context.Context {
+	return context.WithValue(ctx, filterKey{}, filter)
+}
+
+// AcceptsVersion checks if version is accepted by the VersionFilter carried by ctx
+// Every version is accepted if ctx has no filter
+func AcceptsVersion(ctx context.Context, version string) bool {
+	if filter, ok := ctx.Value(filterKey{}).(VersionFilter); ok && filter != nil {
+		return filter(version)
+	}
+	return true
+}
+
+// ContextWithVersionComparer returns a copy of ctx which carries comparer
+// The providers listing several releases (Github, Gitlab, Gitea) use it to find the newest release
+func ContextWithVersionComparer(ctx context.Context, comparer version.Comparer) context.Context {
+	return context.WithValue(ctx, comparerKey{}, comparer)
+}
+
+// VersionComparerFromContext gets the version.Comparer carried by ctx (version.Default if ctx has none)
+func VersionComparerFromContext(ctx context.Context) version.Comparer {
+	comparer, _ := ctx.Value(comparerKey{}).(version.Comparer)
+	return version.OrDefault(comparer)
+}
+
+// comparerOrContext gets comparer, or the version.Comparer of ctx if comparer is nil
+func comparerOrContext(ctx context.Context, comparer version.Comparer) version.Comparer {
+	if comparer != nil {
+		return comparer
+	}
+	return VersionComparerFromContext(ctx)
+}
+
+// isNewerVersion checks if v is newer than newest using the version.Comparer of ctx
+// Every version is newer than an empty newest, a version which can't be parsed is never newer
+// than a version which can
+func isNewerVersion(ctx context.Context, v string, newest string) bool {
+	if newest == "" {
+		return true
+	}
+	comparer := VersionComparerFromContext(ctx)
+	cmp, err := comparer.Compare(v, newest)
+	if err == nil {
+		return cmp > 0
+	}
+	_, err = comparer.Compare(v, v)
+	return err == nil
+}
@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"context"
+)
+
+// Logger records what the providers and the updater are doing
+// The arguments are alternating keys and values, so a *slog.Logger (log/slog) can be used as a Logger
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// nopLogger is a Logger which discards everything
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...interface{}) {}
+func (nopLogger) Info(msg string, args ...interface{})  {}
+func (nopLogger) Warn(msg string, args ...interface{})  {}
+func (nopLogger) Error(msg string, args ...interface{}) {}
+
+// loggerKey is the key of the Logger in a context
+type loggerKey struct{}
+
+// ContextWithLogger returns a copy of ctx which carries logger
+// The ...Context methods of the providers record their steps using this logger
+func ContextWithLogger(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// LoggerFromContext gets the Logger carried by ctx
+// returns a Logger which discards everything if ctx has none
+func LoggerFromContext(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(Logger); ok && logger != nil {
+		return logger
+	}
+	return nopLogger{}
+}
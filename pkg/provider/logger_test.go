@@ -0,0 +1,64 @@
+package provider_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+)
+
+// recordingLogger is a Logger which records the messages
+type recordingLogger struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) record(msg string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.messages = append(l.messages, msg)
+}
+
+func (l *recordingLogger) Debug(msg string, args ...interface{}) { l.record(msg) }
+func (l *recordingLogger) Info(msg string, args ...interface{})  { l.record(msg) }
+func (l *recordingLogger) Warn(msg string, args ...interface{})  { l.record(msg) }
+func (l *recordingLogger) Error(msg string, args ...interface{}) { l.record(msg) }
+
+// logged checks if msg was recorded
+func (l *recordingLogger) logged(msg string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, m := range l.messages {
+		if m == msg {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLoggerFromContext(t *testing.T) {
+	// Does nothing without logger
+	provider.LoggerFromContext(context.Background()).Info("nothing")
+
+	logger := &recordingLogger{}
+	ctx := provider.ContextWithLogger(context.Background(), logger)
+
+	p := &provider.Zip{
+		Path: filepath.Join("testdata", "Allum1-v1.0.0.zip"),
+	}
+	if err := p.OpenContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	err := p.WalkContext(ctx, func(info *provider.FileInfo) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !logger.logged("walking file") {
+		t.Errorf("The walked files should be logged: %v", logger.messages)
+	}
+}
@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ReleaseMetadata describes the latest release
+// It is read from the manifest (manifest.json) published with the release, example:
+//
+//	{"mandatory": false, "min_supported_version": "v1.2.0", "notes": "Security fixes", "rollout_percentage": 25}
+type ReleaseMetadata struct {
+	Version             string   `json:"version"`                      // Version of the release (always the latest version given by the provider)
+	Mandatory           bool     `json:"mandatory"`                    // The release must be installed by every client
+	MinSupportedVersion string   `json:"min_supported_version"`        // (optional) The clients older than this version must update
+	Notes               string   `json:"notes"`                        // (optional) Release notes
+	RolloutPercentage   *float64 `json:"rollout_percentage,omitempty"` // (optional) Percentage of the installations receiving the release (100 by default)
+}
+
+// Rollout gets the percentage of the installations receiving the release (between 0 and 100)
+func (m *ReleaseMetadata) Rollout() float64 {
+	if m.RolloutPercentage == nil || *m.RolloutPercentage > 100 {
+		return 100
+	}
+	if *m.RolloutPercentage < 0 {
+		return 0
+	}
+	return *m.RolloutPercentage
+}
+
+// A MetadataProvider is a Provider which can read the manifest of the latest release
+// Use GetReleaseMetadata to get the metadata of any Provider
+type MetadataProvider interface {
+	GetReleaseMetadataContext(ctx context.Context) (*ReleaseMetadata, error)
+}
+
+// GetReleaseMetadata gets the metadata of the latest release provided by p
+// If p is not a MetadataProvider (or the release has no manifest), only the Version is set
+func GetReleaseMetadata(ctx context.Context, p Provider) (*ReleaseMetadata, error) {
+	if adapter, ok := p.(*contextAdapter); ok {
+		p = adapter.Provider
+	}
+	if metadataProvider, ok := p.(MetadataProvider); ok {
+		return metadataProvider.GetReleaseMetadataContext(ctx)
+	}
+	latestVersion, err := AsContextProvider(p).GetLatestVersionContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ReleaseMetadata{Version: latestVersion}, nil
+}
+
+// parseManifest reads the manifest of the release latestVersion
+func parseManifest(reader io.Reader, latestVersion string) (*ReleaseMetadata, error) {
+	metadata := &ReleaseMetadata{}
+	if err := json.NewDecoder(reader).Decode(metadata); err != nil {
+		return nil, fmt.Errorf("invalid release manifest: %w", err)
+	}
+	metadata.Version = latestVersion
+	return metadata, nil
+}
+
+// downloadManifest downloads and reads the manifest of the release latestVersion
+func downloadManifest(ctx context.Context, url string, latestVersion string) (*ReleaseMetadata, error) {
+	return downloadManifestWithHeader(ctx, url, nil, latestVersion)
+}
+
+// downloadManifestWithHeader is the same as downloadManifest but the request has the headers of header
+func downloadManifestWithHeader(ctx context.Context, url string, header http.Header, latestVersion string) (*ReleaseMetadata, error) {
+	resp, err := httpGetWithHeader(ctx, url, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err = checkStatus(resp, url); err != nil {
+		return nil, err
+	}
+	return parseManifest(resp.Body, latestVersion)
+}
@@ -0,0 +1,121 @@
+package provider_test
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+)
+
+func TestGetReleaseMetadata(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	ctx := context.Background()
+
+	// Local without manifest
+	if err = os.WriteFile(filepath.Join(tmpDir, "VERSION"), []byte("v1.2.0"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	local := &provider.Local{Path: tmpDir}
+	metadata, err := provider.GetReleaseMetadata(ctx, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Version != "v1.2.0" || metadata.Mandatory || metadata.MinSupportedVersion != "" {
+		t.Errorf("Bad metadata without manifest: %+v", metadata)
+	}
+
+	// Local with manifest
+	manifest := `{"version": "ignored", "mandatory": true, "min_supported_version": "v1.1.0", "notes": "fixes"}`
+	if err = os.WriteFile(filepath.Join(tmpDir, "manifest.json"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	metadata, err = provider.GetReleaseMetadata(ctx, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := provider.ReleaseMetadata{Version: "v1.2.0", Mandatory: true, MinSupportedVersion: "v1.1.0", Notes: "fixes"}
+	if *metadata != expected {
+		t.Errorf("Bad metadata from manifest: %+v", metadata)
+	}
+
+	// Zip with manifest
+	zipPath := filepath.Join(tmpDir, "binaries-v1.3.0.zip")
+	file, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zipWriter := zip.NewWriter(file)
+	w, err := zipWriter.Create("manifest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprint(w, `{"min_supported_version": "v1.0.0"}`)
+	zipWriter.Close()
+	file.Close()
+	metadata, err = provider.GetReleaseMetadata(ctx, &provider.Zip{Path: zipPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Version != "v1.3.0" || metadata.MinSupportedVersion != "v1.0.0" {
+		t.Errorf("Bad metadata from zip: %+v", metadata)
+	}
+
+	// Providers without metadata only give the version
+	metadata, err = provider.GetReleaseMetadata(ctx, &provider.Gzip{Path: filepath.Join("testdata", "Allum1-v1.0.0.tar.gz")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Version != "v1.0.0" || metadata.Mandatory {
+		t.Errorf("Bad metadata from gzip: %+v", metadata)
+	}
+
+	// Invalid manifest
+	if err = os.WriteFile(filepath.Join(tmpDir, "manifest.json"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = provider.GetReleaseMetadata(ctx, local); err == nil {
+		t.Error("An invalid manifest should return an error")
+	}
+}
+
+func TestProviderGithubReleaseMetadata(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/project/releases":
+			fmt.Fprintf(w, `[{"tag_name": "v2.0.0", "assets": [
+				{"name": "binaries.zip", "browser_download_url": "%[1]s/download/binaries.zip"},
+				{"name": "manifest.json", "browser_download_url": "%[1]s/download/manifest.json"}
+			]}]`, server.URL)
+		case "/download/manifest.json":
+			fmt.Fprint(w, `{"mandatory": true}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p := &provider.Github{
+		RepositoryURL: "github.com/owner/project",
+		ArchiveName:   "binaries.zip",
+		APIURL:        server.URL,
+	}
+	metadata, err := p.GetReleaseMetadataContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Version != "v2.0.0" || !metadata.Mandatory {
+		t.Errorf("Bad metadata: %+v", metadata)
+	}
+}
@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+	"io"
+)
+
+// ProgressStage describes the step of an update a Progress refers to
+type ProgressStage int
+
+const (
+	// StageDownload is reported while an archive is downloaded
+	StageDownload ProgressStage = iota
+	// StageExtract is reported while files are extracted from an archive
+	StageExtract
+	// StageVerify is reported while the signature of a file is verified
+	StageVerify
+	// StagePatch is reported while the executable is replaced
+	StagePatch
+)
+
+// String returns the name of the stage
+func (s ProgressStage) String() string {
+	switch s {
+	case StageDownload:
+		return "download"
+	case StageExtract:
+		return "extract"
+	case StageVerify:
+		return "verify"
+	case StagePatch:
+		return "patch"
+	}
+	return "unknown"
+}
+
+// Progress describes the progress of a stage
+type Progress struct {
+	Stage   ProgressStage
+	Path    string // URL or path of the file being processed
+	Current int64  // Bytes processed so far
+	Total   int64  // Total bytes to process, -1 if unknown
+}
+
+// Done checks if the stage is complete
+func (p Progress) Done() bool {
+	return p.Total >= 0 && p.Current >= p.Total
+}
+
+// ProgressFunc is called to report the progress of an update
+type ProgressFunc func(progress Progress)
+
+// progressKey is the context key of the ProgressFunc
+type progressKey struct{}
+
+// ContextWithProgress returns a copy of ctx which carries progressFn
+// The providers report their progress to progressFn when they are called with this context
+func ContextWithProgress(ctx context.Context, progressFn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, progressFn)
+}
+
+// ReportProgress reports progress to the ProgressFunc carried by ctx (if any)
+func ReportProgress(ctx context.Context, progress Progress) {
+	if progressFn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok && progressFn != nil {
+		progressFn(progress)
+	}
+}
+
+// progressReader is a reader which reports the number of bytes read
+type progressReader struct {
+	ctx      context.Context
+	reader   io.Reader
+	progress Progress
+}
+
+// Read reads from the underlying reader and reports the progress
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.progress.Current += int64(n)
+		ReportProgress(r.ctx, r.progress)
+	}
+	return n, err
+}
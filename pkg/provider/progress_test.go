@@ -0,0 +1,120 @@
+package provider_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+)
+
+// recordProgress returns a context which records the progress events
+func recordProgress(events *[]provider.Progress) context.Context {
+	return provider.ContextWithProgress(context.Background(), func(progress provider.Progress) {
+		*events = append(*events, progress)
+	})
+}
+
+// lastProgress gets the last progress event of a stage
+func lastProgress(events []provider.Progress, stage provider.ProgressStage) *provider.Progress {
+	var last *provider.Progress
+	for i := range events {
+		if events[i].Stage == stage {
+			last = &events[i]
+		}
+	}
+	return last
+}
+
+func TestProgressGzip(t *testing.T) {
+	var events []provider.Progress
+	p := &provider.Gzip{Path: filepath.Join("testdata", "Allum1-v1.0.0.tar.gz")}
+	if err := p.OpenContext(recordProgress(&events)); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	last := lastProgress(events, provider.StageExtract)
+	if last == nil {
+		t.Fatal("Extraction progress should be reported")
+	}
+	if !last.Done() || last.Path != p.Path {
+		t.Errorf("Bad extraction progress: %+v", *last)
+	}
+	if events[0].Current != 0 {
+		t.Error("The first event should be reported before the extraction starts")
+	}
+}
+
+func TestProgressZip(t *testing.T) {
+	var events []provider.Progress
+	p := &provider.Zip{Path: filepath.Join("testdata", "Allum1-v1.0.0.zip")}
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	dest := filepath.Join(tmpDir, "allum1")
+	if err := p.RetrieveContext(recordProgress(&events), "allum1", dest); err != nil {
+		t.Fatal(err)
+	}
+	last := lastProgress(events, provider.StageExtract)
+	if last == nil {
+		t.Fatal("Extraction progress should be reported")
+	}
+	if !last.Done() || last.Total != 19208 || last.Path != "allum1" {
+		t.Errorf("Bad extraction progress: %+v", *last)
+	}
+}
+
+func TestProgressSecure(t *testing.T) {
+	pubStr := `-----BEGIN RSA PUBLIC KEY-----
+MIICIjANBgkqhkiG9w0BAQEFAAOCAg8AMIICCgKCAgEA4L9SG+I23Rr3rCv8MUWb
+XEF2/SfOQBoYM80pKv4Q6CDICH5wmmWWfTchAEbkKHp3Hx9qP2/aR3mg1qLqWrXZ
+hrYMFKvQCuu+3qGU+KxZfb9qtwIyMSdQdw3yLo7dw/1Kvwcowu6m6OTt/0buF7mr
+UQwDM3EhyOCfKDBOJ8+WbAevvpnCI4fcGT9mrEZ6S2wgIx+6Io5Bbre7+iQ7l9RO
+kE1iKITwXbCtpU3VDRkjtIQ3O0LdGMifJ+DNNIogxiAUGpYZVYVXummX9vBU1whr
+bd2lf9PUB0HLEZPtMFgR3FtQpGqGSi2LXH7C7YSQDHdX/VWRb5kfmjJA/M57tD55
+oe2F3Cxeqi3TNQ/8d+9Ta/8TUCrthyd0dPe0F98HlkyqFh+aIWuaK9mdhh/rzOk8
+on97gotr1tYOdFTK09KHnYrSdCmqfvByOyQnHYvzqN6qwmEI3ufY/i/KTe6QxtWL
+J73aa5rBKLCE/TYT53R2ZhFCXYTPhzwl5LkwYKdKIQ55Z9TfYhjbArjCMTz19Akl
+6qjQcxAmGK2mY2odUkHD/Rfqs02fQoQdnRoi3qhkDW4fDYfcqTnkfTkcEvvJJTuI
+ylMx5Dy8lG6/J7zKWkV6S7h3+K11dWZn7toQVyVU3M2GpEng3b74Pp3Ma7ymoM8J
+SZ5Uz050oR/PoLaSx3xdjFMCAwEAAQ==
+-----END RSA PUBLIC KEY-----`
+	var events []provider.Progress
+	p := &provider.Secure{
+		BackendProvider: &provider.Zip{Path: filepath.Join("testdata", "Allum1Signed-v1.0.0.zip")},
+		PublicKeyPEM:    []byte(pubStr),
+	}
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	dest := filepath.Join(tmpDir, "allum1")
+	if err := p.RetrieveContext(recordProgress(&events), "allum1", dest); err != nil {
+		t.Fatal(err)
+	}
+	if lastProgress(events, provider.StageExtract) == nil {
+		t.Error("Extraction progress of the backend provider should be reported")
+	}
+	last := lastProgress(events, provider.StageVerify)
+	if last == nil || !last.Done() {
+		t.Error("Verification progress should be reported")
+	}
+}
@@ -0,0 +1,248 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+	"github.com/mouuff/go-rocket-update/pkg/version"
+)
+
+// Bitbucket provider finds the archive with the latest version in its name among the downloads of a repository
+// The version is read from the name of the downloads like GetLatestVersionFromPath does
+// (example: binaries-v1.4.53.zip is version "v1.4.53").
+// It only works with Bitbucket Cloud: Bitbucket Server and Data Center have no downloads API.
+type Bitbucket struct {
+	Workspace       string           // Workspace owning the repository
+	Repository      string           // Slug of the repository
+	ArchiveName     string           // (optional) Name of the archives (any zip or tar.gz by default), it can be a template (see TemplateData, {{.Version}} matches any version) or a glob pattern
+	Username        string           // (optional) Username of the app password
+	AppPassword     string           // (optional) App password (for private repositories)
+	Token           string           // (optional) Access token (for private repositories), it is used instead of the app password
+	APIURL          string           // (optional) URL of the Bitbucket Cloud API (https://api.bitbucket.org/2.0 by default), example: a proxy of the API
+	VersionComparer version.Comparer // (optional) Versioning scheme used to find the version in the names (the one of the context, like Updater.VersionComparer, by default)
+
+	tmpDir             string   // temporary directory this is used internally
+	decompressProvider Provider // provider used to decompress the downloaded archive
+	archivePath        string   // path to the downloaded archive (should be in tmpDir)
+}
+
+// bitbucketDownloads struct used to unmarshal a page of downloads from bitbucket
+// https://developer.atlassian.com/cloud/bitbucket/rest/api-group-downloads/
+type bitbucketDownloads struct {
+	Values []bitbucketDownload `json:"values"`
+	Next   string              `json:"next"`
+}
+
+// bitbucketDownload is a file of the downloads of the repository
+type bitbucketDownload struct {
+	Name  string `json:"name"`
+	Links struct {
+		Self struct {
+			Href string `json:"href"`
+		} `json:"self"`
+	} `json:"links"`
+}
+
+// getAPIURL gets the API URL
+func (c *Bitbucket) getAPIURL() string {
+	if c.APIURL != "" {
+		return strings.TrimSuffix(c.APIURL, "/")
+	}
+	return "https://api.bitbucket.org/2.0"
+}
+
+// getDownloadsURL get the downloads URL for the bitbucket repository
+func (c *Bitbucket) getDownloadsURL() (string, error) {
+	if c.Workspace == "" || c.Repository == "" {
+		return "", fmt.Errorf("invalid bitbucket repository: Workspace and Repository are required")
+	}
+	return fmt.Sprintf("%s/repositories/%s/%s/downloads",
+		c.getAPIURL(),
+		url.PathEscape(c.Workspace),
+		url.PathEscape(c.Repository),
+	), nil
+}
+
+// header gets the headers of a request to rawURL
+// the credentials are only sent to the host of the API
+func (c *Bitbucket) header(rawURL string) http.Header {
+	if c.Token == "" && c.AppPassword == "" {
+		return nil
+	}
+	apiURL, err := url.Parse(c.getAPIURL())
+	if err != nil {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host != apiURL.Host {
+		return nil
+	}
+	if c.Token != "" {
+		return http.Header{"Authorization": {"Bearer " + c.Token}}
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte(c.Username + ":" + c.AppPassword))
+	return http.Header{"Authorization": {"Basic " + credentials}}
+}
+
+// getDownloads gets the downloads of the repository
+func (c *Bitbucket) getDownloads(ctx context.Context) ([]bitbucketDownload, error) {
+	downloadsURL, err := c.getDownloadsURL()
+	if err != nil {
+		return nil, err
+	}
+	var downloads []bitbucketDownload
+	for downloadsURL != "" {
+		resp, err := httpGetWithHeader(ctx, downloadsURL, c.header(downloadsURL))
+		if err != nil {
+			return nil, err
+		}
+		page := &bitbucketDownloads{}
+		err = checkStatus(resp, downloadsURL)
+		if err == nil {
+			err = json.NewDecoder(resp.Body).Decode(page)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		downloads = append(downloads, page.Values...)
+		downloadsURL = page.Next
+	}
+	return downloads, nil
+}
+
+// getLatestDownload gets the download with the latest version accepted by the version filter of ctx
+func (c *Bitbucket) getLatestDownload(ctx context.Context) (download *bitbucketDownload, latestVersion string, err error) {
+	downloads, err := c.getDownloads(ctx)
+	if err != nil {
+		return
+	}
+	pattern, err := ExpandTemplate(c.ArchiveName, NewTemplateData(c.Repository, "*"))
+	if err != nil {
+		return
+	}
+	var names []string
+	for _, d := range downloads {
+		names = append(names, d.Name)
+	}
+	name, latestVersion, err := findNewestVersionName(ctx, names, pattern, c.VersionComparer)
+	if err != nil {
+		return nil, "", fmt.Errorf("no archive in the downloads of this bitbucket repository: %w", err)
+	}
+	for i := range downloads {
+		if downloads[i].Name == name {
+			download = &downloads[i]
+		}
+	}
+	LoggerFromContext(ctx).Debug("latest bitbucket download", "name", name, "version", latestVersion)
+	return download, latestVersion, nil
+}
+
+// Open opens the provider
+func (c *Bitbucket) Open() error {
+	return c.OpenContext(context.Background())
+}
+
+// OpenContext opens the provider, the download is cancelled when ctx is done
+// The temporary files are removed if it fails
+func (c *Bitbucket) OpenContext(ctx context.Context) (err error) {
+	defer func() {
+		if err != nil {
+			c.Close()
+		}
+	}()
+	download, _, err := c.getLatestDownload(ctx)
+	if err != nil {
+		return
+	}
+	archiveURL := download.Links.Self.Href
+	if archiveURL == "" {
+		downloadsURL, err := c.getDownloadsURL()
+		if err != nil {
+			return err
+		}
+		archiveURL = downloadsURL + "/" + url.PathEscape(download.Name)
+	}
+	LoggerFromContext(ctx).Info("bitbucket archive found", "name", download.Name, "url", archiveURL)
+
+	c.tmpDir, err = fileio.TempDir()
+	if err != nil {
+		return
+	}
+
+	c.archivePath = filepath.Join(c.tmpDir, filepath.Base(download.Name))
+	err = downloadFileWithHeader(ctx, archiveURL, c.header(archiveURL), c.archivePath)
+	if err != nil {
+		return
+	}
+	c.decompressProvider, err = Decompress(c.archivePath)
+	if err != nil {
+		return
+	}
+	return AsContextProvider(c.decompressProvider).OpenContext(ctx)
+}
+
+// Close closes the provider
+func (c *Bitbucket) Close() error {
+	if c.decompressProvider != nil {
+		c.decompressProvider.Close()
+		c.decompressProvider = nil
+	}
+
+	if len(c.tmpDir) > 0 {
+		os.RemoveAll(c.tmpDir)
+		c.tmpDir = ""
+		c.archivePath = ""
+	}
+	return nil
+}
+
+// DownloadSize gets the size of the downloaded archive
+func (c *Bitbucket) DownloadSize() int64 {
+	return fileSize(c.archivePath)
+}
+
+// GetLatestVersion gets the latest version
+func (c *Bitbucket) GetLatestVersion() (string, error) {
+	return c.GetLatestVersionContext(context.Background())
+}
+
+// GetLatestVersionContext gets the latest version
+func (c *Bitbucket) GetLatestVersionContext(ctx context.Context) (string, error) {
+	_, latestVersion, err := c.getLatestDownload(ctx)
+	return latestVersion, err
+}
+
+// Walk walks all the files provided
+func (c *Bitbucket) Walk(walkFn WalkFunc) error {
+	return c.WalkContext(context.Background(), walkFn)
+}
+
+// WalkContext walks all the files provided
+func (c *Bitbucket) WalkContext(ctx context.Context, walkFn WalkFunc) error {
+	if c.decompressProvider == nil {
+		return ErrNotOpenned
+	}
+	return AsContextProvider(c.decompressProvider).WalkContext(ctx, walkFn)
+}
+
+// Retrieve file relative to "provider" to destination
+func (c *Bitbucket) Retrieve(src string, dest string) error {
+	return c.RetrieveContext(context.Background(), src, dest)
+}
+
+// RetrieveContext retrieves file relative to "provider" to destination
+func (c *Bitbucket) RetrieveContext(ctx context.Context, src string, dest string) error {
+	if c.decompressProvider == nil {
+		return ErrNotOpenned
+	}
+	return AsContextProvider(c.decompressProvider).RetrieveContext(ctx, src, dest)
+}
@@ -0,0 +1,112 @@
+package provider_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+)
+
+func TestProviderBitbucket(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); (!ok || username != "user" || password != "secret") &&
+			r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		downloads := "/repositories/workspace/project/downloads"
+		switch r.URL.Path {
+		case downloads:
+			if r.URL.Query().Get("page") == "" {
+				fmt.Fprintf(w, `{"values": [
+					{"name": "Allum1-v1.0.0.zip", "links": {"self": {"href": "%[1]s%[2]s/Allum1-v1.0.0.zip"}}},
+					{"name": "notes.txt", "links": {"self": {"href": "%[1]s%[2]s/notes.txt"}}}
+				], "next": "%[1]s%[2]s?page=2"}`, server.URL, downloads)
+				return
+			}
+			fmt.Fprintf(w, `{"values": [
+				{"name": "Allum1-v1.1.0.tar.gz", "links": {"self": {"href": "%[1]s%[2]s/Allum1-v1.1.0.tar.gz"}}},
+				{"name": "Allum1-v1.1.0.tar.gz.sha256", "links": {"self": {"href": "%[1]s%[2]s/Allum1-v1.1.0.tar.gz.sha256"}}}
+			]}`, server.URL, downloads)
+		case downloads + "/Allum1-v1.0.0.zip", downloads + "/Allum1-v1.1.0.tar.gz":
+			http.ServeFile(w, r, filepath.Join("testdata", filepath.Base(r.URL.Path)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p := &provider.Bitbucket{
+		Workspace:   "workspace",
+		Repository:  "project",
+		Username:    "user",
+		AppPassword: "secret",
+		APIURL:      server.URL,
+	}
+	latestVersion, err := p.GetLatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latestVersion != "v1.1.0" {
+		t.Errorf("latest version should be v1.1.0, got %s", latestVersion)
+	}
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	archive, err := os.Stat(filepath.Join("testdata", "Allum1-v1.1.0.tar.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := p.DownloadSize(); size != archive.Size() {
+		t.Errorf("DownloadSize() = %d, expected %d", size, archive.Size())
+	}
+	if err := ProviderTestWalkAndRetrieve(p); err != nil {
+		t.Fatal(err)
+	}
+
+	zipProvider := &provider.Bitbucket{
+		Workspace:   "workspace",
+		Repository:  "project",
+		ArchiveName: "Allum1-{{.Version}}.zip",
+		Token:       "token",
+		APIURL:      server.URL,
+	}
+	if err := zipProvider.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer zipProvider.Close()
+	if latestVersion, err = zipProvider.GetLatestVersion(); err != nil {
+		t.Fatal(err)
+	}
+	if latestVersion != "v1.0.0" {
+		t.Errorf("latest version matching ArchiveName should be v1.0.0, got %s", latestVersion)
+	}
+	if err := ProviderTestWalkAndRetrieve(zipProvider); err != nil {
+		t.Fatal(err)
+	}
+
+	badProvider := &provider.Bitbucket{
+		Workspace:  "workspace",
+		Repository: "project",
+		APIURL:     server.URL,
+	}
+	if err := ProviderTestUnavailable(badProvider); err != nil {
+		t.Fatal(err)
+	}
+	badProvider = &provider.Bitbucket{
+		Workspace:   "workspace",
+		Repository:  "project",
+		ArchiveName: "missing-*.zip",
+		Token:       "token",
+		APIURL:      server.URL,
+	}
+	if err := ProviderTestUnavailable(badProvider); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"io"
+)
+
+// contextAdapter is used to turn a Provider into a ContextProvider
+// the context is checked before calling the wrapped provider
+type contextAdapter struct {
+	Provider
+}
+
+// AsContextProvider returns p if it already is a ContextProvider,
+// otherwise p is wrapped so that the context is checked before each call
+// (and between each file for Walk)
+func AsContextProvider(p Provider) ContextProvider {
+	if cp, ok := p.(ContextProvider); ok {
+		return cp
+	}
+	return &contextAdapter{Provider: p}
+}
+
+// GetLatestVersionContext gets the latest version
+func (c *contextAdapter) GetLatestVersionContext(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return c.Provider.GetLatestVersion()
+}
+
+// OpenContext opens the provider
+func (c *contextAdapter) OpenContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Provider.Open()
+}
+
+// WalkContext walks all the files provided
+func (c *contextAdapter) WalkContext(ctx context.Context, walkFn WalkFunc) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Provider.Walk(func(info *FileInfo) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return walkFn(info)
+	})
+}
+
+// RetrieveContext retrieves file relative to "provider" to destination
+func (c *contextAdapter) RetrieveContext(ctx context.Context, src string, dest string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.Provider.Retrieve(src, dest)
+}
+
+// contextReader is a reader which fails as soon as its context is done
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+// Read reads from the underlying reader if the context is not done
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
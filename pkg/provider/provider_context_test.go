@@ -0,0 +1,80 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+)
+
+func TestAsContextProvider(t *testing.T) {
+	p := provider.AsContextProvider(&provider.Local{Path: filepath.Join("testdata", "Allum1")})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := p.OpenContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	err := ProviderTestWalkAndRetrieve(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := 0
+	err = p.WalkContext(ctx, func(info *provider.FileInfo) error {
+		count += 1
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Error("WalkContext() should return context.Canceled")
+	}
+	if count != 1 {
+		t.Error("WalkContext() should stop walking when the context is cancelled")
+	}
+	if _, err = p.GetLatestVersionContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Error("GetLatestVersionContext() should return context.Canceled")
+	}
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	if err = p.RetrieveContext(ctx, "VERSION", filepath.Join(tmpDir, "VERSION")); !errors.Is(err, context.Canceled) {
+		t.Error("RetrieveContext() should return context.Canceled")
+	}
+
+	gzipProvider := &provider.Gzip{}
+	if provider.AsContextProvider(gzipProvider) != gzipProvider {
+		t.Error("AsContextProvider() should not wrap a ContextProvider")
+	}
+}
+
+func TestProviderGzipCancel(t *testing.T) {
+	p := &provider.Gzip{
+		Path: filepath.Join("testdata", "Allum1-v1.0.0.tar.gz"),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := p.OpenContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatal("OpenContext() should return context.Canceled")
+	}
+	if err := p.Walk(func(info *provider.FileInfo) error { return nil }); err == nil {
+		t.Error("Walk() should return an error when the extraction was cancelled")
+	}
+
+	// The provider can be opened again after a cancellation
+	if err := p.OpenContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if err := ProviderTestWalkAndRetrieve(p); err != nil {
+		t.Fatal(err)
+	}
+}
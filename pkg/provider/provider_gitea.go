@@ -0,0 +1,250 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mouuff/go-rocket-update/internal/constant"
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+)
+
+// Gitea provider finds a archive file in the repository's releases to provide files
+// It works with Gitea and Forgejo servers
+type Gitea struct {
+	BaseURL     string  // URL of the server, example: https://gitea.mydomain.tld
+	Owner       string  // Owner of the repository (user or organization)
+	Repository  string  // Name of the repository
+	ArchiveName string  // Archive name (the zip/tar.gz you attach to a release), example: binaries.zip, it can be a template (see TemplateData) or a glob pattern
+	Channel     Channel // (optional) Update channel, prereleases are ignored by default (see Channel)
+	Token       string  // (optional) Access token (for private repositories), it is only sent to the host of BaseURL
+
+	tmpDir             string        // temporary directory this is used internally
+	decompressProvider Provider      // provider used to decompress the downloaded archive
+	archivePath        string        // path to the downloaded archive (should be in tmpDir)
+	latestRelease      *giteaRelease // release found by the last GetLatestVersion, reused by GetReleaseMetadata
+}
+
+// giteaRelease struct used to unmarshal response from gitea
+// https://gitea.com/api/swagger#/repository/repoListReleases
+type giteaRelease struct {
+	TagName    string       `json:"tag_name"`
+	Draft      bool         `json:"draft"`
+	Prerelease bool         `json:"prerelease"`
+	Assets     []giteaAsset `json:"assets"`
+}
+
+// giteaAsset is a file attached to a release
+type giteaAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// getReleasesURL get the releases URL for the gitea repository
+func (c *Gitea) getReleasesURL() (string, error) {
+	if c.BaseURL == "" || c.Owner == "" || c.Repository == "" {
+		return "", fmt.Errorf("invalid gitea repository: BaseURL, Owner and Repository are required")
+	}
+	return fmt.Sprintf("%s/api/v1/repos/%s/%s/releases?limit=50",
+		strings.TrimSuffix(c.BaseURL, "/"),
+		url.PathEscape(c.Owner),
+		url.PathEscape(c.Repository),
+	), nil
+}
+
+// header gets the headers of a request to rawURL
+// the token is only sent to the host of BaseURL
+func (c *Gitea) header(rawURL string) http.Header {
+	if c.Token == "" {
+		return nil
+	}
+	baseURL, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host != baseURL.Host {
+		return nil
+	}
+	return http.Header{"Authorization": {"token " + c.Token}}
+}
+
+// getArchive get the archive name and URL of the latest release
+// ArchiveName is expanded and matched against the assets of the release
+func (c *Gitea) getArchive(ctx context.Context) (name string, archiveURL string, err error) {
+	release, err := c.getLatestRelease(ctx)
+	if err != nil {
+		return
+	}
+	pattern, err := ExpandTemplate(c.ArchiveName, NewTemplateData(c.Repository, release.TagName))
+	if err != nil {
+		return
+	}
+	var names []string
+	for _, asset := range release.Assets {
+		names = append(names, asset.Name)
+	}
+	name, err = FindName(pattern, names)
+	if err != nil {
+		return "", "", fmt.Errorf("asset not found for name: %w", err)
+	}
+	for _, asset := range release.Assets {
+		if asset.Name == name {
+			archiveURL = asset.BrowserDownloadURL
+		}
+	}
+	return
+}
+
+// getLatestRelease gets the newest release of the repository provided by the channel
+// and accepted by the version filter of ctx, the releases are compared with the version.Comparer of ctx
+// They are listed from the most recent, so the pages are read until one of them has an accepted release
+func (c *Gitea) getLatestRelease(ctx context.Context) (*giteaRelease, error) {
+	releasesURL, err := c.getReleasesURL()
+	if err != nil {
+		return nil, err
+	}
+	var latestRelease *giteaRelease
+	for pageURL := releasesURL; pageURL != "" && latestRelease == nil; {
+		var releases []giteaRelease
+		found, next, err := getJSONPage(ctx, pageURL, c.header(pageURL), &releases)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: gitea repository not found: %s/%s", ErrProviderUnavailable, c.Owner, c.Repository)
+		}
+		for i, release := range releases {
+			if !release.Draft && c.Channel.Accepts(release.TagName, release.Prerelease) && AcceptsVersion(ctx, release.TagName) {
+				if latestRelease == nil || isNewerVersion(ctx, release.TagName, latestRelease.TagName) {
+					latestRelease = &releases[i]
+				}
+			}
+		}
+		pageURL = next
+	}
+	if latestRelease == nil {
+		return nil, fmt.Errorf("%w: this gitea project has no releases (matching the channel and the version filter)", ErrFileNotFound)
+	}
+	LoggerFromContext(ctx).Debug("latest gitea release", "tag", latestRelease.TagName, "channel", c.Channel)
+	return latestRelease, nil
+}
+
+// Open opens the provider
+func (c *Gitea) Open() error {
+	return c.OpenContext(context.Background())
+}
+
+// OpenContext opens the provider, the download is cancelled when ctx is done
+// The temporary files are removed if it fails
+func (c *Gitea) OpenContext(ctx context.Context) (err error) {
+	defer func() {
+		if err != nil {
+			c.Close()
+		}
+	}()
+	archiveName, archiveURL, err := c.getArchive(ctx) // get archive of the latest version
+	if err != nil {
+		return
+	}
+	LoggerFromContext(ctx).Info("gitea archive found", "name", archiveName, "url", archiveURL)
+
+	c.tmpDir, err = fileio.TempDir()
+	if err != nil {
+		return
+	}
+
+	c.archivePath = filepath.Join(c.tmpDir, filepath.Base(archiveName))
+	err = downloadFileWithHeader(ctx, archiveURL, c.header(archiveURL), c.archivePath)
+	if err != nil {
+		return
+	}
+	c.decompressProvider, err = Decompress(c.archivePath)
+	if err != nil {
+		return
+	}
+	return AsContextProvider(c.decompressProvider).OpenContext(ctx)
+}
+
+// Close closes the provider
+func (c *Gitea) Close() error {
+	if c.decompressProvider != nil {
+		c.decompressProvider.Close()
+		c.decompressProvider = nil
+	}
+
+	if len(c.tmpDir) > 0 {
+		os.RemoveAll(c.tmpDir)
+		c.tmpDir = ""
+		c.archivePath = ""
+	}
+	return nil
+}
+
+// GetReleaseMetadataContext gets the metadata of the latest release
+// It is read from the asset named manifest.json (if the release has one)
+func (c *Gitea) GetReleaseMetadataContext(ctx context.Context) (*ReleaseMetadata, error) {
+	release := c.latestRelease
+	if release == nil {
+		var err error
+		if release, err = c.getLatestRelease(ctx); err != nil {
+			return nil, err
+		}
+	}
+	for _, asset := range release.Assets {
+		if asset.Name == constant.ManifestRelPath {
+			return downloadManifestWithHeader(ctx, asset.BrowserDownloadURL, c.header(asset.BrowserDownloadURL), release.TagName)
+		}
+	}
+	return &ReleaseMetadata{Version: release.TagName}, nil
+}
+
+// DownloadSize gets the size of the downloaded archive
+func (c *Gitea) DownloadSize() int64 {
+	return fileSize(c.archivePath)
+}
+
+// GetLatestVersion gets the latest version
+func (c *Gitea) GetLatestVersion() (string, error) {
+	return c.GetLatestVersionContext(context.Background())
+}
+
+// GetLatestVersionContext gets the latest version
+func (c *Gitea) GetLatestVersionContext(ctx context.Context) (string, error) {
+	release, err := c.getLatestRelease(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.latestRelease = release
+	return release.TagName, nil
+}
+
+// Walk walks all the files provided
+func (c *Gitea) Walk(walkFn WalkFunc) error {
+	return c.WalkContext(context.Background(), walkFn)
+}
+
+// WalkContext walks all the files provided
+func (c *Gitea) WalkContext(ctx context.Context, walkFn WalkFunc) error {
+	if c.decompressProvider == nil {
+		return ErrNotOpenned
+	}
+	return AsContextProvider(c.decompressProvider).WalkContext(ctx, walkFn)
+}
+
+// Retrieve file relative to "provider" to destination
+func (c *Gitea) Retrieve(src string, dest string) error {
+	return c.RetrieveContext(context.Background(), src, dest)
+}
+
+// RetrieveContext retrieves file relative to "provider" to destination
+func (c *Gitea) RetrieveContext(ctx context.Context, src string, dest string) error {
+	if c.decompressProvider == nil {
+		return ErrNotOpenned
+	}
+	return AsContextProvider(c.decompressProvider).RetrieveContext(ctx, src, dest)
+}
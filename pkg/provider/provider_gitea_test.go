@@ -0,0 +1,116 @@
+package provider_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+)
+
+func TestProviderGitea(t *testing.T) {
+	archive, err := os.ReadFile(filepath.Join("testdata", "Allum1-v1.0.0.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/repos/owner/project/releases":
+			fmt.Fprintf(w, `[
+				{"tag_name": "v0.9.0", "assets": [
+					{"name": "project_v0.9.0_%[1]s.zip", "browser_download_url": "%[2]s/owner/project/releases/download/v0.9.0/archive.zip"}
+				]},
+				{"tag_name": "v1.2.0", "draft": true},
+				{"tag_name": "v1.1.0-rc.1", "prerelease": true, "assets": [
+					{"name": "project_v1.1.0-rc.1_%[1]s.zip", "browser_download_url": "%[2]s/owner/project/releases/download/v1.1.0-rc.1/archive.zip"}
+				]},
+				{"tag_name": "v1.0.0", "assets": [
+					{"name": "project_v1.0.0_%[1]s.zip", "browser_download_url": "%[2]s/owner/project/releases/download/v1.0.0/archive.zip"},
+					{"name": "manifest.json", "browser_download_url": "%[2]s/owner/project/releases/download/v1.0.0/manifest.json"}
+				]}
+			]`, runtime.GOOS, server.URL)
+		case "/owner/project/releases/download/v1.0.0/archive.zip", "/owner/project/releases/download/v1.1.0-rc.1/archive.zip":
+			w.Write(archive)
+		case "/owner/project/releases/download/v1.0.0/manifest.json":
+			fmt.Fprint(w, `{"notes": "first release"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p := &provider.Gitea{
+		BaseURL:     server.URL,
+		Owner:       "owner",
+		Repository:  "project",
+		ArchiveName: "{{.Name}}_{{.Version}}_{{.GOOS}}.zip",
+		Token:       "secret",
+	}
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if err := ProviderTestWalkAndRetrieve(p); err != nil {
+		t.Fatal(err)
+	}
+	latestVersion, err := p.GetLatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latestVersion != "v1.0.0" {
+		t.Errorf("latest version should be v1.0.0, got %s", latestVersion)
+	}
+	metadata, err := provider.GetReleaseMetadata(context.Background(), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Version != "v1.0.0" || metadata.Notes != "first release" {
+		t.Errorf("Bad metadata: %+v", metadata)
+	}
+
+	beta := &provider.Gitea{
+		BaseURL:     server.URL,
+		Owner:       "owner",
+		Repository:  "project",
+		ArchiveName: "{{.Name}}_{{.Version}}_{{.GOOS}}.zip",
+		Channel:     provider.ChannelBeta,
+		Token:       "secret",
+	}
+	if latestVersion, err = beta.GetLatestVersion(); err != nil {
+		t.Fatal(err)
+	}
+	if latestVersion != "v1.1.0-rc.1" {
+		t.Errorf("latest beta version should be v1.1.0-rc.1, got %s", latestVersion)
+	}
+
+	badProvider := &provider.Gitea{
+		BaseURL:     server.URL,
+		Owner:       "owner",
+		Repository:  "project",
+		ArchiveName: "{{.Name}}_{{.Version}}_{{.GOOS}}.zip",
+	}
+	if err := ProviderTestUnavailable(badProvider); err != nil {
+		t.Fatal(err)
+	}
+	badProvider = &provider.Gitea{
+		BaseURL:     server.URL,
+		Owner:       "owner",
+		Repository:  "project",
+		ArchiveName: "missing.zip",
+		Token:       "secret",
+	}
+	if err := badProvider.Open(); err == nil {
+		badProvider.Close()
+		t.Error("Open should fail when the release has no matching asset")
+	}
+}
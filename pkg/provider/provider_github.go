@@ -1,31 +1,45 @@
 package provider
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 
+	"github.com/mouuff/go-rocket-update/internal/constant"
 	"github.com/mouuff/go-rocket-update/internal/fileio"
 )
 
 // Github provider finds a archive file in the repository's releases to provide files
 type Github struct {
-	RepositoryURL string // Repository URL, example github.com/mouuff/go-rocket-update
-	ArchiveName   string // Archive name (the zip/tar.gz you upload for a release on github), example: binaries.zip
+	RepositoryURL string  // Repository URL, example github.com/mouuff/go-rocket-update
+	ArchiveName   string  // Archive name (the zip/tar.gz you upload for a release on github), example: binaries.zip, it can be a template (see TemplateData) or a glob pattern
+	Channel       Channel // (optional) Update channel, prereleases are ignored by default (see Channel)
+	APIURL        string  // (optional) API URL (in case you're using GitHub Enterprise), example: https://github.mydomain.tld/api/v3 to use github.com let it blank
 
-	tmpDir             string   // temporary directory this is used internally
-	decompressProvider Provider // provider used to decompress the downloaded archive
-	archivePath        string   // path to the downloaded archive (should be in tmpDir)
+	tmpDir             string         // temporary directory this is used internally
+	decompressProvider Provider       // provider used to decompress the downloaded archive
+	archivePath        string         // path to the downloaded archive (should be in tmpDir)
+	latestRelease      *githubRelease // release found by the last GetLatestVersion, reused by GetReleaseMetadata
 }
 
-// githubTag struct used to unmarshal response from github
-// https://api.github.com/repos/ownerName/projectName/tags
-type githubTag struct {
-	Name string `json:"name"`
+// githubRelease struct used to unmarshal response from github
+// https://api.github.com/repos/ownerName/projectName/releases
+type githubRelease struct {
+	TagName    string        `json:"tag_name"`
+	Draft      bool          `json:"draft"`
+	Prerelease bool          `json:"prerelease"`
+	Assets     []githubAsset `json:"assets"`
+}
+
+// githubAsset is a file uploaded for a release
+type githubAsset struct {
+	Name               string `json:"name"`
+	State              string `json:"state"` // "uploaded" once the upload is complete
+	BrowserDownloadURL string `json:"browser_download_url"`
 }
 
 // githubRepositoryInfo is used to get the name of the project and the owner name
@@ -48,92 +62,141 @@ func (c *Github) repositoryInfo() (*githubRepositoryInfo, error) {
 	}, nil
 }
 
-// getTagsURL get the tags URL for the github repository
-func (c *Github) getTagsURL() (string, error) {
+// getRepositoryAPIURL get the API URL of the github repository
+func (c *Github) getRepositoryAPIURL() (string, error) {
 	info, err := c.repositoryInfo()
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("https://api.github.com/repos/%s/%s/tags",
+	apiURL := "https://api.github.com"
+	if c.APIURL != "" {
+		apiURL = strings.TrimSuffix(c.APIURL, "/")
+	}
+	return fmt.Sprintf("%s/repos/%s/%s",
+		apiURL,
 		info.RepositoryOwner,
 		info.RepositoryName,
 	), nil
 }
 
-// getArchiveURL get the archive URL for the github repository
-// If no tag is provided then the latest version is selected
-func (c *Github) getArchiveURL(tag string) (string, error) {
-	if len(tag) == 0 {
-		// Get latest version if no tag is provided
-		var err error
-		tag, err = c.GetLatestVersion()
-		if err != nil {
-			return "", err
+// findAsset finds the archive of the release, the ArchiveName is expanded and glob patterns are matched
+// against the assets of the release
+// returns nil if the release has no such asset (or if it is still uploading)
+func (c *Github) findAsset(release *githubRelease) (*githubAsset, error) {
+	info, err := c.repositoryInfo()
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := ExpandTemplate(c.ArchiveName, NewTemplateData(info.RepositoryName, release.TagName))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, asset := range release.Assets {
+		if asset.State == "" || asset.State == "uploaded" {
+			names = append(names, asset.Name)
 		}
 	}
-
-	info, err := c.repositoryInfo()
+	name, err := FindName(pattern, names)
+	if errors.Is(err, ErrFileNotFound) {
+		return nil, nil
+	}
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return fmt.Sprintf("https://github.com/%s/%s/releases/download/%s/%s",
-		info.RepositoryOwner,
-		info.RepositoryName,
-		tag,
-		c.ArchiveName,
-	), nil
+	for i, asset := range release.Assets {
+		if asset.Name == name {
+			return &release.Assets[i], nil
+		}
+	}
+	return nil, nil
 }
 
-// getTags gets tags of the repository
-func (c *Github) getTags() (tags []githubTag, err error) {
-	tagsURL, err := c.getTagsURL()
-	if err != nil {
-		return
+// selectRelease gets the archive of release if the release is provided by the channel
+// and accepted by the version filter of ctx (nil otherwise)
+func (c *Github) selectRelease(ctx context.Context, release *githubRelease) (*githubAsset, error) {
+	if release.Draft || !c.Channel.Accepts(release.TagName, release.Prerelease) || !AcceptsVersion(ctx, release.TagName) {
+		return nil, nil
 	}
-	resp, err := http.Get(tagsURL)
+	return c.findAsset(release)
+}
+
+// getLatestRelease gets the newest release of the repository provided by the channel,
+// accepted by the version filter of ctx and having the archive
+// The releases are compared with the version.Comparer of ctx
+// They are listed from the most recent, so the pages are read until one of them has an accepted release
+func (c *Github) getLatestRelease(ctx context.Context) (*githubRelease, *githubAsset, error) {
+	repositoryURL, err := c.getRepositoryAPIURL()
 	if err != nil {
-		return
+		return nil, nil, err
 	}
-	defer resp.Body.Close()
-	err = json.NewDecoder(resp.Body).Decode(&tags)
-	if err != nil {
-		return
+	var latestRelease *githubRelease
+	var latestAsset *githubAsset
+	for pageURL := repositoryURL + "/releases?per_page=100"; pageURL != "" && latestRelease == nil; {
+		var releases []githubRelease
+		found, next, err := getJSONPage(ctx, pageURL, nil, &releases)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !found {
+			return nil, nil, fmt.Errorf("%w: github repository not found: %s", ErrProviderUnavailable, c.RepositoryURL)
+		}
+		for i := range releases {
+			if latestRelease != nil && !isNewerVersion(ctx, releases[i].TagName, latestRelease.TagName) {
+				continue
+			}
+			asset, err := c.selectRelease(ctx, &releases[i])
+			if err != nil {
+				return nil, nil, err
+			}
+			if asset != nil {
+				latestRelease, latestAsset = &releases[i], asset
+			}
+		}
+		pageURL = next
+	}
+	if latestRelease == nil {
+		return nil, nil, fmt.Errorf("%w: this github project has no releases (matching the channel, the version filter and the archive name %s)",
+			ErrFileNotFound, c.ArchiveName)
 	}
-	return
+	LoggerFromContext(ctx).Debug("latest github release", "tag", latestRelease.TagName, "channel", c.Channel)
+	return latestRelease, latestAsset, nil
 }
 
 // Open opens the provider
-func (c *Github) Open() (err error) {
-	archiveURL, err := c.getArchiveURL("") // get archive url for latest version
-	if err != nil {
-		return
-	}
-	resp, err := http.Get(archiveURL)
+func (c *Github) Open() error {
+	return c.OpenContext(context.Background())
+}
+
+// OpenContext opens the provider, the download is cancelled when ctx is done
+// The temporary files are removed if it fails
+func (c *Github) OpenContext(ctx context.Context) (err error) {
+	defer func() {
+		if err != nil {
+			c.Close()
+		}
+	}()
+	_, asset, err := c.getLatestRelease(ctx) // get archive of the latest version
 	if err != nil {
 		return
 	}
-	defer resp.Body.Close()
+	LoggerFromContext(ctx).Info("github archive found", "name", asset.Name, "url", asset.BrowserDownloadURL)
 
 	c.tmpDir, err = fileio.TempDir()
 	if err != nil {
 		return
 	}
 
-	c.archivePath = filepath.Join(c.tmpDir, c.ArchiveName)
-	archiveFile, err := os.Create(c.archivePath)
-	if err != nil {
-		return
-	}
-	_, err = io.Copy(archiveFile, resp.Body)
-	archiveFile.Close()
+	c.archivePath = filepath.Join(c.tmpDir, filepath.Base(asset.Name))
+	err = downloadFile(ctx, asset.BrowserDownloadURL, c.archivePath)
 	if err != nil {
 		return
 	}
 	c.decompressProvider, err = Decompress(c.archivePath)
 	if err != nil {
-		return nil
+		return
 	}
-	return c.decompressProvider.Open()
+	return AsContextProvider(c.decompressProvider).OpenContext(ctx)
 }
 
 // Close closes the provider
@@ -151,28 +214,67 @@ func (c *Github) Close() error {
 	return nil
 }
 
+// GetReleaseMetadataContext gets the metadata of the latest release
+// It is read from the asset named manifest.json (if the release has one)
+func (c *Github) GetReleaseMetadataContext(ctx context.Context) (*ReleaseMetadata, error) {
+	release := c.latestRelease
+	if release == nil {
+		var err error
+		if release, _, err = c.getLatestRelease(ctx); err != nil {
+			return nil, err
+		}
+	}
+	for _, asset := range release.Assets {
+		if asset.Name == constant.ManifestRelPath {
+			return downloadManifest(ctx, asset.BrowserDownloadURL, release.TagName)
+		}
+	}
+	return &ReleaseMetadata{Version: release.TagName}, nil
+}
+
+// DownloadSize gets the size of the downloaded archive
+func (c *Github) DownloadSize() int64 {
+	return fileSize(c.archivePath)
+}
+
 // GetLatestVersion gets the latest version
 func (c *Github) GetLatestVersion() (string, error) {
-	tags, err := c.getTags()
+	return c.GetLatestVersionContext(context.Background())
+}
+
+// GetLatestVersionContext gets the latest version
+func (c *Github) GetLatestVersionContext(ctx context.Context) (string, error) {
+	release, _, err := c.getLatestRelease(ctx)
 	if err != nil {
 		return "", err
 	}
-	if len(tags) < 1 {
-		return "", fmt.Errorf("this github project has no tags")
-	}
-	return tags[0].Name, nil
+	c.latestRelease = release
+	return release.TagName, nil
 }
 
 // Walk walks all the files provided
 func (c *Github) Walk(walkFn WalkFunc) error {
+	return c.WalkContext(context.Background(), walkFn)
+}
+
+// WalkContext walks all the files provided
+func (c *Github) WalkContext(ctx context.Context, walkFn WalkFunc) error {
 	if c.decompressProvider == nil {
 		// TODO specify error
 		return ErrNotOpenned
 	}
-	return c.decompressProvider.Walk(walkFn)
+	return AsContextProvider(c.decompressProvider).WalkContext(ctx, walkFn)
 }
 
 // Retrieve file relative to "provider" to destination
 func (c *Github) Retrieve(src string, dest string) error {
-	return c.decompressProvider.Retrieve(src, dest)
+	return c.RetrieveContext(context.Background(), src, dest)
+}
+
+// RetrieveContext retrieves file relative to "provider" to destination
+func (c *Github) RetrieveContext(ctx context.Context, src string, dest string) error {
+	if c.decompressProvider == nil {
+		return ErrNotOpenned
+	}
+	return AsContextProvider(c.decompressProvider).RetrieveContext(ctx, src, dest)
 }
@@ -1,7 +1,12 @@
 package provider_test
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
@@ -46,7 +51,181 @@ func TestProviderGithub(t *testing.T) {
 		ArchiveName:   fmt.Sprintf("binaries_%s.zip", runtime.GOOS),
 	}
 	_, err = badProvider.GetLatestVersion()
-	if err == nil || !strings.Contains(err.Error(), "tags") {
-		t.Fatal("Should not get version without tags")
+	if err == nil || !strings.Contains(err.Error(), "releases") {
+		t.Fatal("Should not get version without releases")
+	}
+}
+
+func TestProviderGithubChannel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/project/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		asset := `[{"name": "binaries.zip", "browser_download_url": "https://example.com/binaries.zip"}]`
+		fmt.Fprintf(w, `[
+			{"tag_name": "v1.3.0-alpha.1", "prerelease": true, "assets": %[1]s},
+			{"tag_name": "v1.2.0", "draft": true, "assets": %[1]s},
+			{"tag_name": "v1.2.0-rc.1", "prerelease": true, "assets": %[1]s},
+			{"tag_name": "v1.1.1", "prerelease": true, "assets": %[1]s},
+			{"tag_name": "v1.1.0", "assets": %[1]s}
+		]`, asset)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		channel  provider.Channel
+		expected string
+	}{
+		{"", "v1.1.0"},
+		{provider.ChannelStable, "v1.1.0"},
+		{provider.ChannelBeta, "v1.2.0-rc.1"},
+		{provider.ChannelAlpha, "v1.3.0-alpha.1"},
+	}
+	for _, test := range tests {
+		p := &provider.Github{
+			RepositoryURL: "github.com/owner/project",
+			ArchiveName:   "binaries.zip",
+			Channel:       test.channel,
+			APIURL:        server.URL,
+		}
+		version, err := p.GetLatestVersion()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != test.expected {
+			t.Errorf("GetLatestVersion() = %s with channel %q, expected %s", version, test.channel, test.expected)
+		}
+	}
+}
+
+func TestProviderGithubArchiveGlob(t *testing.T) {
+	archive, err := os.ReadFile(filepath.Join("testdata", "Allum1-v1.0.0.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/project/releases":
+			fmt.Fprintf(w, `[{"tag_name": "v1.0.0", "assets": [
+				{"name": "project_v1.0.0_%[1]s.zip", "browser_download_url": "%[2]s/download/archive.zip"},
+				{"name": "project_v1.0.0_%[1]s.zip.sha256", "browser_download_url": "%[2]s/download/archive.zip.sha256"}
+			]}]`, runtime.GOOS, server.URL)
+		case "/download/archive.zip":
+			w.Write(archive)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p := &provider.Github{
+		RepositoryURL: "github.com/owner/project",
+		ArchiveName:   "{{.Name}}_{{.Version}}_{{.GOOS}}.*",
+		APIURL:        server.URL,
+	}
+	if err := p.Open(); err == nil {
+		p.Close()
+		t.Fatal("Open should fail when the archive name is ambiguous")
+	}
+
+	p.ArchiveName = "{{.Name}}_{{.Version}}_{{.GOOS}}.z?p"
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if size := p.DownloadSize(); size != int64(len(archive)) {
+		t.Errorf("DownloadSize() = %d, expected %d", size, len(archive))
+	}
+	if err := ProviderTestWalkAndRetrieve(p); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProviderGithubReleaseAssets(t *testing.T) {
+	archive, err := os.ReadFile(filepath.Join("testdata", "Allum1-v1.0.0.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	releasesStatus := http.StatusOK
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/project/releases":
+			if releasesStatus != http.StatusOK {
+				http.Error(w, "error", releasesStatus)
+				return
+			}
+			// the assets of v1.3.0 are still uploading and v1.0.0 was published after v1.1.0
+			fmt.Fprintf(w, `[
+				{"tag_name": "v1.0.0", "assets": [
+					{"name": "binaries.zip", "state": "uploaded", "browser_download_url": "%[1]s/download/v1.0.0/binaries.zip"}
+				]},
+				{"tag_name": "v1.3.0", "assets": [
+					{"name": "binaries.zip", "state": "new", "browser_download_url": "%[1]s/download/v1.3.0/binaries.zip"}
+				]},
+				{"tag_name": "v1.2.0"},
+				{"tag_name": "v1.1.0", "assets": [
+					{"name": "binaries.zip", "state": "uploaded", "browser_download_url": "%[1]s/download/v1.1.0/binaries.zip"},
+					{"name": "broken.zip", "state": "uploaded", "browser_download_url": "%[1]s/download/v1.1.0/broken.zip"}
+				]}
+			]`, server.URL)
+		case "/download/v1.1.0/binaries.zip":
+			w.Write(archive)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p := &provider.Github{
+		RepositoryURL: "github.com/owner/project",
+		ArchiveName:   "binaries.zip",
+		APIURL:        server.URL,
+	}
+	latestVersion, err := p.GetLatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latestVersion != "v1.1.0" {
+		t.Errorf("The latest release with the archive is v1.1.0, got %s", latestVersion)
+	}
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if err := ProviderTestWalkAndRetrieve(p); err != nil {
+		t.Fatal(err)
+	}
+
+	// The archive is missing on the server
+	badProvider := &provider.Github{
+		RepositoryURL: "github.com/owner/project",
+		ArchiveName:   "broken.zip",
+		APIURL:        server.URL,
+	}
+	if err := badProvider.Open(); !provider.IsUnavailable(err) {
+		t.Errorf("Open should fail when the archive can't be downloaded, got %v", err)
+	}
+	badProvider = &provider.Github{
+		RepositoryURL: "github.com/owner/project",
+		ArchiveName:   "missing.zip",
+		APIURL:        server.URL,
+	}
+	if _, err := badProvider.GetLatestVersion(); !errors.Is(err, provider.ErrFileNotFound) {
+		t.Errorf("GetLatestVersion should return ErrFileNotFound without release having the archive, got %v", err)
+	}
+	badProvider = &provider.Github{
+		RepositoryURL: "github.com/owner/unknown",
+		ArchiveName:   "binaries.zip",
+		APIURL:        server.URL,
+	}
+	if err := ProviderTestUnavailable(badProvider); err != nil {
+		t.Fatal(err)
+	}
+	releasesStatus = http.StatusInternalServerError
+	if _, err := p.GetLatestVersion(); !errors.Is(err, provider.ErrProviderUnavailable) {
+		t.Errorf("GetLatestVersion should return ErrProviderUnavailable on server errors, got %v", err)
 	}
 }
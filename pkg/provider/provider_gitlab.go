@@ -1,33 +1,35 @@
 package provider
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
-	"strings"
 
+	"github.com/mouuff/go-rocket-update/internal/constant"
 	"github.com/mouuff/go-rocket-update/internal/fileio"
 )
 
 // Gitlab provider finds a archive file in the repository's releases to provide files
 type Gitlab struct {
 	ProjectID   int
-	ArchiveName string // ArchiveName (the archive you upload for a release on gitlab), example: binaries.zip
-	ApiURI      string // ApiURI (in case you're using a private gitlab server), example: gitlab.mydomain.tld/api/v4/projects/%d/releases to use gitlab.com let it blank
-
-	tmpDir             string   // temporary directory this is used internally
-	decompressProvider Provider // provider used to decompress the downloaded archive
-	decompressPath     string   // path to the downloaded archive (should be in tmpDir)
+	ArchiveName string  // ArchiveName (the archive you upload for a release on gitlab), example: binaries.zip, it can be a template (see TemplateData) or a glob pattern
+	ApiURI      string  // ApiURI (in case you're using a private gitlab server), example: gitlab.mydomain.tld/api/v4/projects/%d/releases to use gitlab.com let it blank
+	Channel     Channel // (optional) Update channel, prereleases are ignored by default (see Channel)
+
+	tmpDir             string         // temporary directory this is used internally
+	decompressProvider Provider       // provider used to decompress the downloaded archive
+	decompressPath     string         // path to the downloaded archive (should be in tmpDir)
+	latestRelease      *gitlabRelease // release found by the last GetLatestVersion, reused by GetReleaseMetadata
 }
 
 // gitlabRelease struct used to unmarshal response from gitlab
 // https://gitlab.com/api/v4/projects/24021648/releases
 type gitlabRelease struct {
-	TagName string               `json:"tag_name"`
-	Assets  *gitlabReleaseAssets `json:"assets"`
+	TagName         string               `json:"tag_name"`
+	UpcomingRelease bool                 `json:"upcoming_release"`
+	Assets          *gitlabReleaseAssets `json:"assets"`
 }
 
 type gitlabReleaseAssets struct {
@@ -40,92 +42,123 @@ type gitlabReleaseLink struct {
 }
 
 // getReleasesURL get the releases URL for the gitlab repository
+// The releases are requested by pages of 100 (the maximum of gitlab)
 func (c *Gitlab) getReleasesURL() (string, error) {
+	releasesURL := fmt.Sprintf("https://gitlab.com/api/v4/projects/%d/releases", c.ProjectID)
 	if c.ApiURI != "" {
-		return fmt.Sprintf(c.ApiURI,
-			c.ProjectID,
-		), nil
+		releasesURL = fmt.Sprintf(c.ApiURI, c.ProjectID)
 	}
-
-	return fmt.Sprintf("https://gitlab.com/api/v4/projects/%d/releases",
-		c.ProjectID,
-	), nil
-}
-
-// getArchiveURL get the archive URL for the gitlab repository
-// the latest version is selected
-func (c *Gitlab) getArchiveURL() (string, error) {
-	release, err := c.getLatestRelease()
+	u, err := url.Parse(releasesURL)
 	if err != nil {
 		return "", err
 	}
-	for _, link := range release.Assets.Links {
-		if strings.HasSuffix(link.Name, c.ArchiveName) {
-			return link.DirectURL, nil
-		}
+	query := u.Query()
+	if query.Get("per_page") == "" {
+		query.Set("per_page", "100")
+		u.RawQuery = query.Encode()
 	}
-	return "", fmt.Errorf("link not found for name: %s", c.ArchiveName)
+	return u.String(), nil
 }
 
-// getReleases gets tags of the repository
-func (c *Gitlab) getReleases() (releases []gitlabRelease, err error) {
-	releasesURL, err := c.getReleasesURL()
+// getArchive get the archive name and URL of the latest release
+// ArchiveName is expanded (TemplateData.Name is empty) and matched against the names of the release links
+func (c *Gitlab) getArchive(ctx context.Context) (name string, archiveURL string, err error) {
+	release, err := c.getLatestRelease(ctx)
 	if err != nil {
 		return
 	}
-	resp, err := http.Get(releasesURL)
+	pattern, err := ExpandTemplate(c.ArchiveName, NewTemplateData("", release.TagName))
 	if err != nil {
 		return
 	}
-	defer resp.Body.Close()
-	err = json.NewDecoder(resp.Body).Decode(&releases)
+	if release.Assets == nil {
+		return "", "", fmt.Errorf("link not found for name: %s", pattern)
+	}
+	var names []string
+	for _, link := range release.Assets.Links {
+		names = append(names, link.Name)
+	}
+	name, err = FindName(pattern, names)
 	if err != nil {
-		return
+		return "", "", fmt.Errorf("link not found for name: %w", err)
+	}
+	for _, link := range release.Assets.Links {
+		if link.Name == name {
+			archiveURL = link.DirectURL
+		}
 	}
 	return
 }
 
-// getReleases gets tags of the repository
-func (c *Gitlab) getLatestRelease() (*gitlabRelease, error) {
-	releases, err := c.getReleases()
+// getLatestRelease gets the newest release of the repository provided by the channel
+// and accepted by the version filter of ctx, the releases are compared with the version.Comparer of ctx
+// They are listed from the most recent, so the pages are read until one of them has an accepted release
+// gitlab has no prerelease flag so only the tag is used to find the channel of a release
+func (c *Gitlab) getLatestRelease(ctx context.Context) (*gitlabRelease, error) {
+	releasesURL, err := c.getReleasesURL()
 	if err != nil {
 		return nil, err
 	}
-	if len(releases) < 1 {
-		return nil, fmt.Errorf("this gitlab project has no releases")
+	var latestRelease *gitlabRelease
+	for pageURL := releasesURL; pageURL != "" && latestRelease == nil; {
+		var releases []gitlabRelease
+		found, next, err := getJSONPage(ctx, pageURL, nil, &releases)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: gitlab project not found: %d", ErrProviderUnavailable, c.ProjectID)
+		}
+		for i, release := range releases {
+			if !release.UpcomingRelease && c.Channel.Accepts(release.TagName, false) && AcceptsVersion(ctx, release.TagName) {
+				if latestRelease == nil || isNewerVersion(ctx, release.TagName, latestRelease.TagName) {
+					latestRelease = &releases[i]
+				}
+			}
+		}
+		pageURL = next
+	}
+	if latestRelease == nil {
+		return nil, fmt.Errorf("%w: this gitlab project has no releases (matching the channel and the version filter)", ErrFileNotFound)
 	}
-	return &releases[0], nil
+	LoggerFromContext(ctx).Debug("latest gitlab release", "tag", latestRelease.TagName, "channel", c.Channel)
+	return latestRelease, nil
 }
 
 // Open opens the provider
-func (c *Gitlab) Open() (err error) {
-	archiveURL, err := c.getArchiveURL() // get archive url for latest version
-	if err != nil {
-		return
-	}
-	resp, err := http.Get(archiveURL)
+func (c *Gitlab) Open() error {
+	return c.OpenContext(context.Background())
+}
+
+// OpenContext opens the provider, the download is cancelled when ctx is done
+// The temporary files are removed if it fails
+func (c *Gitlab) OpenContext(ctx context.Context) (err error) {
+	defer func() {
+		if err != nil {
+			c.Close()
+		}
+	}()
+	archiveName, archiveURL, err := c.getArchive(ctx) // get archive of the latest version
 	if err != nil {
 		return
 	}
-	defer resp.Body.Close()
+	LoggerFromContext(ctx).Info("gitlab archive found", "name", archiveName, "url", archiveURL)
 
 	c.tmpDir, err = fileio.TempDir()
 	if err != nil {
 		return
 	}
 
-	c.decompressPath = filepath.Join(c.tmpDir, c.ArchiveName)
-	archiveFile, err := os.Create(c.decompressPath)
+	c.decompressPath = filepath.Join(c.tmpDir, filepath.Base(archiveName))
+	err = downloadFile(ctx, archiveURL, c.decompressPath)
 	if err != nil {
 		return
 	}
-	_, err = io.Copy(archiveFile, resp.Body)
-	archiveFile.Close()
+	c.decompressProvider, err = Decompress(c.decompressPath)
 	if err != nil {
 		return
 	}
-	c.decompressProvider, err = Decompress(c.decompressPath)
-	return c.decompressProvider.Open()
+	return AsContextProvider(c.decompressProvider).OpenContext(ctx)
 }
 
 // Close closes the provider
@@ -143,25 +176,69 @@ func (c *Gitlab) Close() error {
 	return nil
 }
 
+// GetReleaseMetadataContext gets the metadata of the latest release
+// It is read from the link named manifest.json (if the release has one)
+func (c *Gitlab) GetReleaseMetadataContext(ctx context.Context) (*ReleaseMetadata, error) {
+	release := c.latestRelease
+	if release == nil {
+		var err error
+		if release, err = c.getLatestRelease(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if release.Assets != nil {
+		for _, link := range release.Assets.Links {
+			if link.Name == constant.ManifestRelPath {
+				return downloadManifest(ctx, link.DirectURL, release.TagName)
+			}
+		}
+	}
+	return &ReleaseMetadata{Version: release.TagName}, nil
+}
+
+// DownloadSize gets the size of the downloaded archive
+func (c *Gitlab) DownloadSize() int64 {
+	return fileSize(c.decompressPath)
+}
+
 // GetLatestVersion gets the latest version
 func (c *Gitlab) GetLatestVersion() (string, error) {
-	release, err := c.getLatestRelease()
+	return c.GetLatestVersionContext(context.Background())
+}
+
+// GetLatestVersionContext gets the latest version
+func (c *Gitlab) GetLatestVersionContext(ctx context.Context) (string, error) {
+	release, err := c.getLatestRelease(ctx)
 	if err != nil {
 		return "", err
 	}
+	c.latestRelease = release
 	return release.TagName, nil
 }
 
 // Walk walks all the files provided
 func (c *Gitlab) Walk(walkFn WalkFunc) error {
+	return c.WalkContext(context.Background(), walkFn)
+}
+
+// WalkContext walks all the files provided
+func (c *Gitlab) WalkContext(ctx context.Context, walkFn WalkFunc) error {
 	if c.decompressProvider == nil {
 		// TODO specify error
 		return ErrNotOpenned
 	}
-	return c.decompressProvider.Walk(walkFn)
+	return AsContextProvider(c.decompressProvider).WalkContext(ctx, walkFn)
 }
 
 // Retrieve file relative to "provider" to destination
 func (c *Gitlab) Retrieve(src string, dest string) error {
-	return c.decompressProvider.Retrieve(src, dest)
+	return c.RetrieveContext(context.Background(), src, dest)
+}
+
+// RetrieveContext retrieves file relative to "provider" to destination
+func (c *Gitlab) RetrieveContext(ctx context.Context, src string, dest string) error {
+	if c.decompressProvider == nil {
+		return ErrNotOpenned
+	}
+	return AsContextProvider(c.decompressProvider).RetrieveContext(ctx, src, dest)
 }
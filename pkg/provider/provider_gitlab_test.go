@@ -1,7 +1,11 @@
 package provider_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"runtime"
 	"testing"
 
@@ -59,3 +63,76 @@ func TestProviderGitlab(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestProviderGitlabChannel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v4/projects/43/releases" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, "<html>Service Unavailable</html>")
+			return
+		}
+		if r.URL.Path != "/api/v4/projects/42/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("per_page") != "100" {
+			t.Errorf("The releases should be requested by pages of 100: %s", r.URL)
+		}
+		// the stable releases are on the second page
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"tag_name": "v1.0.2"}, {"tag_name": "v1.1.0"}]`)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s/api/v4/projects/42/releases?page=2&per_page=100>; rel="next"`, r.Host))
+		fmt.Fprint(w, `[
+			{"tag_name": "v1.3.0", "upcoming_release": true},
+			{"tag_name": "v1.3.0-alpha.1"},
+			{"tag_name": "v1.2.0-beta.2"}
+		]`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		channel  provider.Channel
+		expected string
+	}{
+		{"", "v1.1.0"},
+		{provider.ChannelBeta, "v1.2.0-beta.2"},
+		{provider.ChannelAlpha, "v1.3.0-alpha.1"},
+	}
+	for _, test := range tests {
+		p := &provider.Gitlab{
+			ProjectID:   42,
+			ArchiveName: "binaries.zip",
+			ApiURI:      server.URL + "/api/v4/projects/%d/releases",
+			Channel:     test.channel,
+		}
+		version, err := p.GetLatestVersion()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != test.expected {
+			t.Errorf("GetLatestVersion() = %s with channel %q, expected %s", version, test.channel, test.expected)
+		}
+	}
+
+	for _, projectID := range []int{43, 44} {
+		p := &provider.Gitlab{
+			ProjectID:   projectID,
+			ArchiveName: "binaries.zip",
+			ApiURI:      server.URL + "/api/v4/projects/%d/releases",
+		}
+		if _, err := p.GetLatestVersion(); !errors.Is(err, provider.ErrProviderUnavailable) {
+			t.Errorf("GetLatestVersion() should return ErrProviderUnavailable for project %d, got %v", projectID, err)
+		}
+	}
+	p := &provider.Gitlab{
+		ProjectID:   42,
+		ArchiveName: "binaries.zip",
+		ApiURI:      server.URL + "/api/v4/projects/%d/releases",
+	}
+	ctx := provider.ContextWithVersionFilter(context.Background(), func(string) bool { return false })
+	if _, err := p.GetLatestVersionContext(ctx); !errors.Is(err, provider.ErrFileNotFound) {
+		t.Errorf("GetLatestVersionContext() should return ErrFileNotFound without accepted release, got %v", err)
+	}
+}
@@ -3,35 +3,51 @@ package provider
 import (
 	"archive/tar"
 	"compress/gzip"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 
 	"github.com/mouuff/go-rocket-update/internal/fileio"
+	"github.com/mouuff/go-rocket-update/pkg/version"
 )
 
 // Gzip provider
 type Gzip struct {
-	Path          string // Path of the Gzip file (provider.GlobNewestFile might help)
-	tmpDir        string
-	localProvider *Local
+	Path            string           // Path of the Gzip file (provider.GlobNewestFile might help)
+	VersionComparer version.Comparer // (optional) Versioning scheme used to find the version in Path (the one of the context, like Updater.VersionComparer, by default)
+	tmpDir          string
+	localProvider   *Local
 }
 
 // extractGzip extracts gzip file to a folder
-func extractGzip(tarball, dest string) error {
+// the extraction is aborted when ctx is done
+// the progress is reported with StageExtract (in compressed bytes)
+func extractGzip(ctx context.Context, tarball, dest string) error {
 	reader, err := os.Open(tarball)
 	if err != nil {
 		return err
 	}
 	defer reader.Close()
-	gzipReader, err := gzip.NewReader(reader)
+	progress := Progress{Stage: StageExtract, Path: tarball, Total: -1}
+	if info, err := reader.Stat(); err == nil {
+		progress.Total = info.Size()
+	}
+	ReportProgress(ctx, progress)
+	gzipReader, err := gzip.NewReader(&contextReader{
+		ctx:    ctx,
+		reader: &progressReader{ctx: ctx, reader: reader, progress: progress},
+	})
 	if err != nil {
 		return err
 	}
 	defer gzipReader.Close()
 	tarReader := tar.NewReader(gzipReader)
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		header, err := tarReader.Next()
 		if err == io.EOF {
 			break
@@ -41,6 +57,7 @@ func extractGzip(tarball, dest string) error {
 
 		path := filepath.Join(dest, header.Name)
 		info := header.FileInfo()
+		LoggerFromContext(ctx).Debug("extracting file", "archive", tarball, "path", header.Name, "size", header.Size)
 		if header.Typeflag == tar.TypeDir {
 			if err = os.MkdirAll(path, info.Mode()); err != nil {
 				return err
@@ -57,11 +74,21 @@ func extractGzip(tarball, dest string) error {
 			}
 		}
 	}
+	if progress.Total >= 0 {
+		progress.Current = progress.Total
+		ReportProgress(ctx, progress)
+	}
 	return nil
 }
 
 // Open opens the provider
-func (c *Gzip) Open() (err error) {
+func (c *Gzip) Open() error {
+	return c.OpenContext(context.Background())
+}
+
+// OpenContext opens the provider, the extraction is aborted when ctx is done
+// The temporary files are removed if it fails
+func (c *Gzip) OpenContext(ctx context.Context) (err error) {
 	if c.tmpDir != "" {
 		// If Open() has already been called we just ignore
 		return nil
@@ -70,8 +97,9 @@ func (c *Gzip) Open() (err error) {
 	if err != nil {
 		return
 	}
-	err = extractGzip(c.Path, c.tmpDir)
+	err = extractGzip(ctx, c.Path, c.tmpDir)
 	if err != nil {
+		c.Close()
 		return err
 	}
 	c.localProvider = &Local{
@@ -96,21 +124,39 @@ func (c *Gzip) Close() (err error) {
 
 // GetLatestVersion gets the latest version
 func (c *Gzip) GetLatestVersion() (string, error) {
-	return GetLatestVersionFromPath(c.Path)
+	return c.GetLatestVersionContext(context.Background())
+}
+
+// GetLatestVersionContext gets the latest version
+func (c *Gzip) GetLatestVersionContext(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return GetLatestVersionFromPathWithComparer(c.Path, comparerOrContext(ctx, c.VersionComparer))
 }
 
 // Walk walks all the files provided
 func (c *Gzip) Walk(walkFn WalkFunc) error {
+	return c.WalkContext(context.Background(), walkFn)
+}
+
+// WalkContext walks all the files provided
+func (c *Gzip) WalkContext(ctx context.Context, walkFn WalkFunc) error {
 	if c.localProvider == nil {
 		return fmt.Errorf("nil c.localProvider")
 	}
-	return c.localProvider.Walk(walkFn)
+	return AsContextProvider(c.localProvider).WalkContext(ctx, walkFn)
 }
 
 // Retrieve file relative to "provider" to destination
 func (c *Gzip) Retrieve(src string, dest string) error {
+	return c.RetrieveContext(context.Background(), src, dest)
+}
+
+// RetrieveContext retrieves file relative to "provider" to destination
+func (c *Gzip) RetrieveContext(ctx context.Context, src string, dest string) error {
 	if c.localProvider == nil {
 		return fmt.Errorf("nil c.localProvider")
 	}
-	return c.localProvider.Retrieve(src, dest)
+	return AsContextProvider(c.localProvider).RetrieveContext(ctx, src, dest)
 }
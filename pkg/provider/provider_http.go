@@ -0,0 +1,257 @@
+package provider
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/mouuff/go-rocket-update/internal/crypto"
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+)
+
+// DefaultHTTPManifestName is the name of the manifest used by the HTTP provider when ManifestName is not set
+const DefaultHTTPManifestName = "latest.json"
+
+// ErrChecksumMismatch is returned when a downloaded file does not match its size or checksum
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// HTTP provider downloads the archive of the current platform listed in a JSON manifest
+// published on a web server (nginx, S3 website...), example of manifest:
+//
+//	{
+//	  "version": "v1.2.0",
+//	  "platforms": {
+//	    "linux-amd64": {"url": "myapp_linux_amd64.zip", "size": 1048576, "sha256": "9f86d0..."},
+//	    "windows-amd64": {"url": "https://cdn.example.com/myapp_windows_amd64.zip"}
+//	  }
+//	}
+//
+// Relative URLs are resolved against the URL of the manifest, size and sha256 are optional.
+// The fields of ReleaseMetadata (mandatory, notes...) can also be set in the manifest.
+type HTTP struct {
+	BaseURL      string      // URL of the directory containing the manifest, example: https://example.com/myapp/
+	ManifestName string      // (optional) Name of the manifest (DefaultHTTPManifestName by default)
+	Platform     string      // (optional) Key of the archive in the manifest (GOOS-GOARCH by default, example: linux-amd64)
+	Header       http.Header // (optional) Headers of the requests, example: Authorization (only sent to the host of the manifest)
+
+	tmpDir             string   // temporary directory this is used internally
+	decompressProvider Provider // provider used to decompress the downloaded archive
+	archivePath        string   // path to the downloaded archive (should be in tmpDir)
+}
+
+// httpManifest struct used to unmarshal the manifest
+type httpManifest struct {
+	ReleaseMetadata
+	Platforms map[string]httpArchive `json:"platforms"`
+}
+
+// httpArchive is the archive of a platform
+type httpArchive struct {
+	URL    string `json:"url"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// getManifestURL gets the URL of the manifest
+func (c *HTTP) getManifestURL() (*url.URL, error) {
+	manifestName := c.ManifestName
+	if manifestName == "" {
+		manifestName = DefaultHTTPManifestName
+	}
+	baseURL, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if !strings.HasSuffix(baseURL.Path, "/") {
+		baseURL.Path += "/"
+	}
+	return baseURL.Parse(manifestName)
+}
+
+// getPlatform gets the key of the archive in the manifest
+func (c *HTTP) getPlatform() string {
+	if c.Platform != "" {
+		return c.Platform
+	}
+	return runtime.GOOS + "-" + runtime.GOARCH
+}
+
+// getManifest downloads the manifest
+func (c *HTTP) getManifest(ctx context.Context) (*url.URL, *httpManifest, error) {
+	manifestURL, err := c.getManifestURL()
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := httpGetWithHeader(ctx, manifestURL.String(), c.Header)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if err = checkStatus(resp, manifestURL.String()); err != nil {
+		return nil, nil, err
+	}
+	manifest := &httpManifest{}
+	if err = json.NewDecoder(resp.Body).Decode(manifest); err != nil {
+		return nil, nil, fmt.Errorf("invalid manifest %s: %w", manifestURL, err)
+	}
+	if manifest.Version == "" {
+		return nil, nil, fmt.Errorf("invalid manifest %s: no version", manifestURL)
+	}
+	return manifestURL, manifest, nil
+}
+
+// getArchive gets the archive of the platform and its URL
+func (c *HTTP) getArchive(ctx context.Context) (*httpArchive, *url.URL, http.Header, error) {
+	manifestURL, manifest, err := c.getManifest(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	archive, ok := manifest.Platforms[c.getPlatform()]
+	if !ok || archive.URL == "" {
+		return nil, nil, nil, fmt.Errorf("%w: no archive for %s in %s", ErrFileNotFound, c.getPlatform(), manifestURL)
+	}
+	archiveURL, err := manifestURL.Parse(archive.URL)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid archive URL: %w", err)
+	}
+	var header http.Header
+	if archiveURL.Host == manifestURL.Host {
+		header = c.Header
+	}
+	return &archive, archiveURL, header, nil
+}
+
+// verifyArchive checks the size and the checksum of the downloaded archive
+func (c *HTTP) verifyArchive(archive *httpArchive) error {
+	if archive.Size > 0 && fileSize(c.archivePath) != archive.Size {
+		return fmt.Errorf("%w: %s has %d bytes instead of %d", ErrChecksumMismatch, archive.URL, fileSize(c.archivePath), archive.Size)
+	}
+	if archive.SHA256 == "" {
+		return nil
+	}
+	checksum, err := crypto.ChecksumFileSHA256(c.archivePath)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(hex.EncodeToString(checksum), archive.SHA256) {
+		return fmt.Errorf("%w: sha256 of %s is %x", ErrChecksumMismatch, archive.URL, checksum)
+	}
+	return nil
+}
+
+// Open opens the provider
+func (c *HTTP) Open() error {
+	return c.OpenContext(context.Background())
+}
+
+// OpenContext opens the provider, the download is cancelled when ctx is done
+// The temporary files are removed if it fails
+func (c *HTTP) OpenContext(ctx context.Context) (err error) {
+	defer func() {
+		if err != nil {
+			c.Close()
+		}
+	}()
+	archive, archiveURL, header, err := c.getArchive(ctx)
+	if err != nil {
+		return
+	}
+	LoggerFromContext(ctx).Info("http archive found", "platform", c.getPlatform(), "url", archiveURL)
+
+	c.tmpDir, err = fileio.TempDir()
+	if err != nil {
+		return
+	}
+
+	c.archivePath = filepath.Join(c.tmpDir, path.Base(archiveURL.Path))
+	err = downloadFileWithHeader(ctx, archiveURL.String(), header, c.archivePath)
+	if err != nil {
+		return
+	}
+	if err = c.verifyArchive(archive); err != nil {
+		return
+	}
+	c.decompressProvider, err = Decompress(c.archivePath)
+	if err != nil {
+		return
+	}
+	return AsContextProvider(c.decompressProvider).OpenContext(ctx)
+}
+
+// Close closes the provider
+func (c *HTTP) Close() error {
+	if c.decompressProvider != nil {
+		c.decompressProvider.Close()
+		c.decompressProvider = nil
+	}
+
+	if len(c.tmpDir) > 0 {
+		os.RemoveAll(c.tmpDir)
+		c.tmpDir = ""
+		c.archivePath = ""
+	}
+	return nil
+}
+
+// GetReleaseMetadataContext gets the metadata of the latest release from the manifest
+func (c *HTTP) GetReleaseMetadataContext(ctx context.Context) (*ReleaseMetadata, error) {
+	_, manifest, err := c.getManifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &manifest.ReleaseMetadata, nil
+}
+
+// DownloadSize gets the size of the downloaded archive
+func (c *HTTP) DownloadSize() int64 {
+	return fileSize(c.archivePath)
+}
+
+// GetLatestVersion gets the latest version
+func (c *HTTP) GetLatestVersion() (string, error) {
+	return c.GetLatestVersionContext(context.Background())
+}
+
+// GetLatestVersionContext gets the latest version
+func (c *HTTP) GetLatestVersionContext(ctx context.Context) (string, error) {
+	_, manifest, err := c.getManifest(ctx)
+	if err != nil {
+		return "", err
+	}
+	return manifest.Version, nil
+}
+
+// Walk walks all the files provided
+func (c *HTTP) Walk(walkFn WalkFunc) error {
+	return c.WalkContext(context.Background(), walkFn)
+}
+
+// WalkContext walks all the files provided
+func (c *HTTP) WalkContext(ctx context.Context, walkFn WalkFunc) error {
+	if c.decompressProvider == nil {
+		return ErrNotOpenned
+	}
+	return AsContextProvider(c.decompressProvider).WalkContext(ctx, walkFn)
+}
+
+// Retrieve file relative to "provider" to destination
+func (c *HTTP) Retrieve(src string, dest string) error {
+	return c.RetrieveContext(context.Background(), src, dest)
+}
+
+// RetrieveContext retrieves file relative to "provider" to destination
+func (c *HTTP) RetrieveContext(ctx context.Context, src string, dest string) error {
+	if c.decompressProvider == nil {
+		return ErrNotOpenned
+	}
+	return AsContextProvider(c.decompressProvider).RetrieveContext(ctx, src, dest)
+}
@@ -0,0 +1,81 @@
+package provider_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+)
+
+func TestProviderHTTP(t *testing.T) {
+	archive, err := os.ReadFile(filepath.Join("testdata", "Allum1-v1.0.0.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksum := sha256.Sum256(archive)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/app/latest.json":
+			fmt.Fprintf(w, `{"version": "v1.0.0", "mandatory": true, "platforms": {
+				"test-good": {"url": "archives/Allum1-v1.0.0.zip", "size": %[1]d, "sha256": "%[2]x"},
+				"test-bad-checksum": {"url": "archives/Allum1-v1.0.0.zip", "sha256": "%[3]x"},
+				"test-missing": {"url": "archives/missing.zip"}
+			}}`, len(archive), checksum, sha256.Sum256(nil))
+		case "/app/archives/Allum1-v1.0.0.zip":
+			w.Write(archive)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	header := http.Header{"Authorization": {"Bearer token"}}
+
+	p := &provider.HTTP{BaseURL: server.URL + "/app", Platform: "test-good", Header: header}
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if size := p.DownloadSize(); size != int64(len(archive)) {
+		t.Errorf("DownloadSize() = %d, expected %d", size, len(archive))
+	}
+	if err := ProviderTestWalkAndRetrieve(p); err != nil {
+		t.Fatal(err)
+	}
+	metadata, err := provider.GetReleaseMetadata(context.Background(), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Version != "v1.0.0" || !metadata.Mandatory {
+		t.Errorf("Bad metadata: %+v", metadata)
+	}
+
+	badProvider := &provider.HTTP{BaseURL: server.URL + "/app", Platform: "test-bad-checksum", Header: header}
+	if err := badProvider.Open(); !errors.Is(err, provider.ErrChecksumMismatch) {
+		t.Errorf("Open should return ErrChecksumMismatch, got %v", err)
+	}
+	badProvider = &provider.HTTP{BaseURL: server.URL + "/app", Platform: "test-missing", Header: header}
+	if err := badProvider.Open(); !provider.IsUnavailable(err) {
+		t.Errorf("Open should fail when the archive is missing, got %v", err)
+	}
+	badProvider = &provider.HTTP{BaseURL: server.URL + "/app", Platform: "test-unknown", Header: header}
+	if err := badProvider.Open(); !errors.Is(err, provider.ErrFileNotFound) {
+		t.Errorf("Open should return ErrFileNotFound without archive for the platform, got %v", err)
+	}
+	if err := ProviderTestUnavailable(&provider.HTTP{BaseURL: server.URL + "/app", Platform: "test-good"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ProviderTestUnavailable(&provider.HTTP{BaseURL: server.URL + "/other", Header: header}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,9 +1,11 @@
 package provider
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 
+	"github.com/mouuff/go-rocket-update/internal/constant"
 	"github.com/mouuff/go-rocket-update/internal/fileio"
 )
 
@@ -38,6 +40,23 @@ func (c *Local) GetLatestVersion() (string, error) {
 	return string(content), nil
 }
 
+// GetReleaseMetadataContext gets the metadata of the latest release from the file manifest.json (if it exists)
+func (c *Local) GetReleaseMetadataContext(ctx context.Context) (*ReleaseMetadata, error) {
+	latestVersion, err := c.GetLatestVersion()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filepath.Join(c.Path, constant.ManifestRelPath))
+	if os.IsNotExist(err) {
+		return &ReleaseMetadata{Version: latestVersion}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseManifest(file, latestVersion)
+}
+
 // Walk walks all the files provided
 func (c *Local) Walk(walkFn WalkFunc) error {
 	if !c.openned {
@@ -55,6 +74,7 @@ func (c *Local) Walk(walkFn WalkFunc) error {
 		return walkFn(&FileInfo{
 			Path: relPath,
 			Mode: info.Mode(),
+			Size: info.Size(),
 		})
 	})
 }
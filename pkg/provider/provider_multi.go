@@ -0,0 +1,180 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+)
+
+// Multi provider uses the first available provider of a list
+// The provider which succeeded in GetLatestVersion() or Open() is used for
+// every following call until Close() is called, so the files never come from different providers.
+// If the selected provider fails to Open(), the next providers are only tried if they provide the same version.
+type Multi struct {
+	Providers      []Provider           // Providers by order of preference
+	ShouldFailover func(err error) bool // (optional) Decides if the next provider should be tried after an error (IsUnavailable by default)
+
+	selected        int    // index of the selected provider (only valid if hasSelected)
+	hasSelected     bool   // true if a provider have been selected
+	selectedVersion string // latest version of the selected provider
+	openned         bool
+}
+
+// IsUnavailable checks if an error is caused by a provider being unavailable (network or missing files)
+// Context cancellation is not considered as a provider being unavailable
+func IsUnavailable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	return errors.Is(err, ErrProviderUnavailable) ||
+		errors.Is(err, ErrFileNotFound) ||
+		errors.Is(err, os.ErrNotExist) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &netErr)
+}
+
+// shouldFailover checks if the next provider should be tried
+func (c *Multi) shouldFailover(err error) bool {
+	if c.ShouldFailover != nil {
+		return c.ShouldFailover(err)
+	}
+	return IsUnavailable(err)
+}
+
+// selectedProvider gets the selected provider
+func (c *Multi) selectedProvider() ContextProvider {
+	if !c.hasSelected {
+		return nil
+	}
+	return AsContextProvider(c.Providers[c.selected])
+}
+
+// GetReleaseMetadataContext gets the metadata of the latest release of the selected provider
+// (or the first available provider if none is selected)
+func (c *Multi) GetReleaseMetadataContext(ctx context.Context) (*ReleaseMetadata, error) {
+	if !c.hasSelected {
+		if _, err := c.GetLatestVersionContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return GetReleaseMetadata(ctx, c.Providers[c.selected])
+}
+
+// DownloadSize gets the number of bytes downloaded by the selected provider
+func (c *Multi) DownloadSize() int64 {
+	if !c.openned {
+		return -1
+	}
+	return DownloadSize(c.Providers[c.selected])
+}
+
+// GetLatestVersion gets the latest version of the first available provider
+func (c *Multi) GetLatestVersion() (string, error) {
+	return c.GetLatestVersionContext(context.Background())
+}
+
+// GetLatestVersionContext gets the latest version of the first available provider
+func (c *Multi) GetLatestVersionContext(ctx context.Context) (string, error) {
+	if c.openned {
+		return c.selectedVersion, nil
+	}
+	var lastErr error = ErrProviderUnavailable
+	for i, p := range c.Providers {
+		latestVersion, err := AsContextProvider(p).GetLatestVersionContext(ctx)
+		if err == nil {
+			c.selected = i
+			c.hasSelected = true
+			c.selectedVersion = latestVersion
+			return latestVersion, nil
+		}
+		if !c.shouldFailover(err) {
+			return "", err
+		}
+		lastErr = err
+	}
+	return "", fmt.Errorf("no provider available: %w", lastErr)
+}
+
+// Open opens the selected provider (or the first available provider if none is selected)
+func (c *Multi) Open() error {
+	return c.OpenContext(context.Background())
+}
+
+// OpenContext opens the selected provider (or the first available provider if none is selected)
+func (c *Multi) OpenContext(ctx context.Context) error {
+	if c.openned {
+		return nil
+	}
+	if !c.hasSelected {
+		if _, err := c.GetLatestVersionContext(ctx); err != nil {
+			return err
+		}
+	}
+	var lastErr error = ErrProviderUnavailable
+	for i := range c.Providers {
+		// The selected provider is tried first
+		index := (c.selected + i) % len(c.Providers)
+		p := AsContextProvider(c.Providers[index])
+		if index != c.selected {
+			latestVersion, err := p.GetLatestVersionContext(ctx)
+			if err != nil || latestVersion != c.selectedVersion {
+				continue
+			}
+		}
+		err := p.OpenContext(ctx)
+		if err == nil {
+			c.selected = index
+			c.openned = true
+			return nil
+		}
+		p.Close()
+		if !c.shouldFailover(err) {
+			return err
+		}
+		lastErr = err
+	}
+	return fmt.Errorf("no provider available for version %s: %w", c.selectedVersion, lastErr)
+}
+
+// Close closes the selected provider, the next call to GetLatestVersion() or Open()
+// will select a provider again
+func (c *Multi) Close() (err error) {
+	if c.openned {
+		err = c.selectedProvider().Close()
+	}
+	c.openned = false
+	c.hasSelected = false
+	c.selected = 0
+	c.selectedVersion = ""
+	return
+}
+
+// Walk walks all the files of the selected provider
+func (c *Multi) Walk(walkFn WalkFunc) error {
+	return c.WalkContext(context.Background(), walkFn)
+}
+
+// WalkContext walks all the files of the selected provider
+func (c *Multi) WalkContext(ctx context.Context, walkFn WalkFunc) error {
+	if !c.openned {
+		return ErrNotOpenned
+	}
+	return c.selectedProvider().WalkContext(ctx, walkFn)
+}
+
+// Retrieve file relative to the selected provider to destination
+func (c *Multi) Retrieve(src string, dest string) error {
+	return c.RetrieveContext(context.Background(), src, dest)
+}
+
+// RetrieveContext retrieves file relative to the selected provider to destination
+func (c *Multi) RetrieveContext(ctx context.Context, src string, dest string) error {
+	if !c.openned {
+		return ErrNotOpenned
+	}
+	return c.selectedProvider().RetrieveContext(ctx, src, dest)
+}
@@ -0,0 +1,121 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+)
+
+func TestProviderMulti(t *testing.T) {
+	p := &provider.Multi{
+		Providers: []provider.Provider{
+			&provider.Local{Path: filepath.Join("testdata", "doesnotexists")},
+			&provider.Zip{Path: filepath.Join("testdata", "Allum1-v1.0.0.zip")},
+			&provider.Gzip{Path: filepath.Join("testdata", "Allum1-v1.1.0.tar.gz")},
+		},
+	}
+	version, err := p.GetLatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "v1.0.0" {
+		t.Error("The first available provider should be used, got version " + version)
+	}
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	err = ProviderTestWalkAndRetrieve(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	badProvider := &provider.Multi{
+		Providers: []provider.Provider{
+			&provider.Local{Path: filepath.Join("testdata", "doesnotexists")},
+			&provider.Zip{Path: filepath.Join("testdata", "doesnotexists.zip")},
+		},
+	}
+	err = ProviderTestUnavailable(badProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProviderMultiOpenFailover(t *testing.T) {
+	// The version of the first provider is found in its path but it can't be openned
+	p := &provider.Multi{
+		Providers: []provider.Provider{
+			&provider.Zip{Path: filepath.Join("testdata", "doesnotexists-v1.0.0.zip")},
+			&provider.Gzip{Path: filepath.Join("testdata", "Allum1-v1.1.0.tar.gz")},
+			&provider.Gzip{Path: filepath.Join("testdata", "Allum1-v1.0.0.tar.gz")},
+		},
+	}
+	version, err := p.GetLatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Open(); err != nil {
+		t.Fatal(err)
+	}
+	// Only the provider with the same version should be used
+	version2, err := p.GetLatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "v1.0.0" || version2 != version {
+		t.Error("The version should not change after Open()")
+	}
+	err = ProviderTestWalkAndRetrieve(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Close()
+
+	p.Providers = p.Providers[:2]
+	if err := p.Open(); err == nil {
+		t.Error("Open() should not use a provider with another version")
+	}
+	p.Close()
+}
+
+func TestProviderMultiShouldFailover(t *testing.T) {
+	errNoFailover := errors.New("no failover")
+	p := &provider.Multi{
+		Providers: []provider.Provider{
+			&provider.Local{Path: filepath.Join("testdata", "doesnotexists")},
+			&provider.Zip{Path: filepath.Join("testdata", "Allum1-v1.0.0.zip")},
+		},
+		ShouldFailover: func(err error) bool {
+			return false
+		},
+	}
+	if _, err := p.GetLatestVersion(); err == nil {
+		t.Error("GetLatestVersion() should fail when failover is disabled")
+	}
+
+	p.ShouldFailover = nil
+	p.Providers[0] = &provider.Secure{
+		BackendProvider: &provider.Local{Path: filepath.Join("testdata", "Allum1")},
+		PublicKeyPEM:    []byte("invalid"),
+	}
+	if err := p.Open(); err == nil || provider.IsUnavailable(err) {
+		t.Errorf("Open() should not failover on an invalid key, got %v", err)
+	}
+	p.Close()
+
+	if provider.IsUnavailable(fmt.Errorf("wrapped: %w", context.Canceled)) {
+		t.Error("A cancellation should not be considered as unavailable")
+	}
+	if provider.IsUnavailable(errNoFailover) {
+		t.Error("An unknown error should not be considered as unavailable")
+	}
+	if !provider.IsUnavailable(fmt.Errorf("wrapped: %w", provider.ErrProviderUnavailable)) {
+		t.Error("ErrProviderUnavailable should be considered as unavailable")
+	}
+}
@@ -0,0 +1,256 @@
+package provider
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+	"github.com/mouuff/go-rocket-update/pkg/version"
+)
+
+// S3 provider finds the archive with the latest version in its name among the objects of an S3-compatible bucket
+// The version is read from the name of the objects like GetLatestVersionFromPath does
+// (example: myapp/binaries-v1.4.53.zip is version "v1.4.53").
+// The requests are signed with AWS Signature Version 4 when AccessKeyID is set.
+type S3 struct {
+	Bucket          string           // Name of the bucket
+	Region          string           // (optional) Region of the bucket (us-east-1 by default)
+	Endpoint        string           // (optional) URL of the S3 API (https://s3.<Region>.amazonaws.com by default), example: https://minio.mydomain.tld
+	Prefix          string           // (optional) Only the objects with this key prefix are listed, example: myapp/
+	ArchiveName     string           // (optional) Name of the archives (any zip or tar.gz by default), it can be a template (see TemplateData, {{.Version}} matches any version) or a glob pattern
+	AccessKeyID     string           // (optional) Access key, the requests are anonymous without it
+	SecretAccessKey string           // (optional) Secret key of AccessKeyID
+	SessionToken    string           // (optional) Session token of temporary credentials
+	VersionComparer version.Comparer // (optional) Versioning scheme used to find the version in the names (the one of the context, like Updater.VersionComparer, by default)
+
+	tmpDir             string   // temporary directory this is used internally
+	decompressProvider Provider // provider used to decompress the downloaded archive
+	archivePath        string   // path to the downloaded archive (should be in tmpDir)
+}
+
+// s3ListBucketResult struct used to unmarshal the response of ListObjectsV2
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_ListObjectsV2.html
+type s3ListBucketResult struct {
+	Contents              []s3Object `xml:"Contents"`
+	IsTruncated           bool       `xml:"IsTruncated"`
+	NextContinuationToken string     `xml:"NextContinuationToken"`
+}
+
+// s3Object is an object of the bucket
+type s3Object struct {
+	Key  string `xml:"Key"`
+	Size int64  `xml:"Size"`
+}
+
+// getRegion gets the region of the bucket
+func (c *S3) getRegion() string {
+	if c.Region != "" {
+		return c.Region
+	}
+	return "us-east-1"
+}
+
+// getURL gets the path-style URL of key in the bucket (the bucket itself if key is empty)
+func (c *S3) getURL(key string, query map[string]string) (*url.URL, error) {
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", c.getRegion())
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+	if c.Bucket == "" {
+		return nil, fmt.Errorf("invalid S3 bucket: empty name")
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + c.Bucket + "/" + key
+	u.RawPath = awsURIEncode(u.Path, false)
+	u.RawQuery = awsQuery(query)
+	return u, nil
+}
+
+// get sends a GET request for key (signed if there are credentials)
+func (c *S3) get(ctx context.Context, key string, query map[string]string) (*http.Response, error) {
+	u, err := c.getURL(key, query)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpGetWithHeader(ctx, u.String(), c.header(u))
+	if err != nil {
+		return nil, err
+	}
+	if err = checkStatus(resp, u.String()); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// header gets the headers of a request on u
+func (c *S3) header(u *url.URL) http.Header {
+	header := http.Header{}
+	if c.AccessKeyID != "" {
+		credentials := &awsCredentials{
+			AccessKeyID:     c.AccessKeyID,
+			SecretAccessKey: c.SecretAccessKey,
+			SessionToken:    c.SessionToken,
+		}
+		credentials.sign(header, http.MethodGet, u, c.getRegion(), "s3", time.Now())
+	}
+	return header
+}
+
+// listObjects lists the objects under Prefix
+func (c *S3) listObjects(ctx context.Context) ([]s3Object, error) {
+	var objects []s3Object
+	query := map[string]string{"list-type": "2", "prefix": c.Prefix}
+	for {
+		resp, err := c.get(ctx, "", query)
+		if err != nil {
+			return nil, err
+		}
+		result := &s3ListBucketResult{}
+		err = xml.NewDecoder(resp.Body).Decode(result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("invalid S3 object list: %w", err)
+		}
+		objects = append(objects, result.Contents...)
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		query["continuation-token"] = result.NextContinuationToken
+	}
+}
+
+// getLatestArchive gets the key and the version of the archive with the latest version
+func (c *S3) getLatestArchive(ctx context.Context) (key string, latestVersion string, err error) {
+	objects, err := c.listObjects(ctx)
+	if err != nil {
+		return
+	}
+	pattern, err := ExpandTemplate(c.ArchiveName, NewTemplateData("", "*"))
+	if err != nil {
+		return
+	}
+	keys := map[string]string{}
+	var names []string
+	for _, object := range objects {
+		name := path.Base(object.Key)
+		if _, ok := keys[name]; !ok {
+			names = append(names, name)
+		}
+		keys[name] = object.Key
+	}
+	name, latestVersion, err := findNewestVersionName(ctx, names, pattern, c.VersionComparer)
+	if err != nil {
+		return "", "", fmt.Errorf("no archive in bucket %s: %w", c.Bucket, err)
+	}
+	LoggerFromContext(ctx).Debug("latest s3 archive", "key", keys[name], "version", latestVersion)
+	return keys[name], latestVersion, nil
+}
+
+// Open opens the provider
+func (c *S3) Open() error {
+	return c.OpenContext(context.Background())
+}
+
+// OpenContext opens the provider, the download is cancelled when ctx is done
+// The temporary files are removed if it fails
+func (c *S3) OpenContext(ctx context.Context) (err error) {
+	defer func() {
+		if err != nil {
+			c.Close()
+		}
+	}()
+	key, _, err := c.getLatestArchive(ctx)
+	if err != nil {
+		return
+	}
+	u, err := c.getURL(key, nil)
+	if err != nil {
+		return
+	}
+	LoggerFromContext(ctx).Info("s3 archive found", "key", key, "url", u)
+
+	c.tmpDir, err = fileio.TempDir()
+	if err != nil {
+		return
+	}
+
+	c.archivePath = filepath.Join(c.tmpDir, path.Base(key))
+	err = downloadFileWithHeader(ctx, u.String(), c.header(u), c.archivePath)
+	if err != nil {
+		return
+	}
+	c.decompressProvider, err = Decompress(c.archivePath)
+	if err != nil {
+		return
+	}
+	return AsContextProvider(c.decompressProvider).OpenContext(ctx)
+}
+
+// Close closes the provider
+func (c *S3) Close() error {
+	if c.decompressProvider != nil {
+		c.decompressProvider.Close()
+		c.decompressProvider = nil
+	}
+
+	if len(c.tmpDir) > 0 {
+		os.RemoveAll(c.tmpDir)
+		c.tmpDir = ""
+		c.archivePath = ""
+	}
+	return nil
+}
+
+// DownloadSize gets the size of the downloaded archive
+func (c *S3) DownloadSize() int64 {
+	return fileSize(c.archivePath)
+}
+
+// GetLatestVersion gets the latest version
+func (c *S3) GetLatestVersion() (string, error) {
+	return c.GetLatestVersionContext(context.Background())
+}
+
+// GetLatestVersionContext gets the latest version
+func (c *S3) GetLatestVersionContext(ctx context.Context) (string, error) {
+	_, latestVersion, err := c.getLatestArchive(ctx)
+	return latestVersion, err
+}
+
+// Walk walks all the files provided
+func (c *S3) Walk(walkFn WalkFunc) error {
+	return c.WalkContext(context.Background(), walkFn)
+}
+
+// WalkContext walks all the files provided
+func (c *S3) WalkContext(ctx context.Context, walkFn WalkFunc) error {
+	if c.decompressProvider == nil {
+		return ErrNotOpenned
+	}
+	return AsContextProvider(c.decompressProvider).WalkContext(ctx, walkFn)
+}
+
+// Retrieve file relative to "provider" to destination
+func (c *S3) Retrieve(src string, dest string) error {
+	return c.RetrieveContext(context.Background(), src, dest)
+}
+
+// RetrieveContext retrieves file relative to "provider" to destination
+func (c *S3) RetrieveContext(ctx context.Context, src string, dest string) error {
+	if c.decompressProvider == nil {
+		return ErrNotOpenned
+	}
+	return AsContextProvider(c.decompressProvider).RetrieveContext(ctx, src, dest)
+}
@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"context"
 	"crypto/rsa"
 	"os"
 	"path/filepath"
@@ -22,14 +23,20 @@ type Secure struct {
 }
 
 // Open the provider
-func (c *Secure) Open() (err error) {
+func (c *Secure) Open() error {
+	return c.OpenContext(context.Background())
+}
+
+// OpenContext opens the provider
+func (c *Secure) OpenContext(ctx context.Context) (err error) {
 	if c.PublicKey == nil {
 		c.PublicKey, err = crypto.ParsePemPublicKey(c.PublicKeyPEM)
 		if err != nil {
 			return
 		}
 	}
-	err = c.BackendProvider.Open()
+	backend := AsContextProvider(c.BackendProvider)
+	err = backend.OpenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -39,7 +46,7 @@ func (c *Secure) Open() (err error) {
 	}
 	defer os.RemoveAll(tmpDir)
 	tmpFile := filepath.Join(tmpDir, constant.SignatureRelPath)
-	err = c.BackendProvider.Retrieve(constant.SignatureRelPath, tmpFile)
+	err = backend.RetrieveContext(ctx, constant.SignatureRelPath, tmpFile)
 	if err != nil {
 		// TODO defines error
 		return
@@ -49,6 +56,7 @@ func (c *Secure) Open() (err error) {
 		// TODO defines error
 		return
 	}
+	LoggerFromContext(ctx).Debug("signatures loaded", "count", len(c.signatures.SignaturesMap))
 	return nil
 }
 
@@ -57,26 +65,68 @@ func (c *Secure) Close() error {
 	return c.BackendProvider.Close()
 }
 
+// GetReleaseMetadataContext gets the metadata of the latest release from the backend provider
+// The manifest is not signed
+func (c *Secure) GetReleaseMetadataContext(ctx context.Context) (*ReleaseMetadata, error) {
+	return GetReleaseMetadata(ctx, c.BackendProvider)
+}
+
+// DownloadSize gets the number of bytes downloaded by the backend provider
+func (c *Secure) DownloadSize() int64 {
+	return DownloadSize(c.BackendProvider)
+}
+
+// HasSignature checks if there is a signature for the file at path
+// Retrieve fails for the files without signature
+func (c *Secure) HasSignature(path string) (bool, error) {
+	if c.signatures == nil {
+		return false, ErrNotOpenned
+	}
+	_, err := c.signatures.Get(path)
+	return err == nil, nil
+}
+
 // GetLatestVersion gets the latest version
 func (c *Secure) GetLatestVersion() (string, error) {
-	return c.BackendProvider.GetLatestVersion()
+	return c.GetLatestVersionContext(context.Background())
+}
+
+// GetLatestVersionContext gets the latest version
+func (c *Secure) GetLatestVersionContext(ctx context.Context) (string, error) {
+	return AsContextProvider(c.BackendProvider).GetLatestVersionContext(ctx)
 }
 
 // Walk all the files provided
 func (c *Secure) Walk(walkFn WalkFunc) error {
-	return c.BackendProvider.Walk(walkFn)
+	return c.WalkContext(context.Background(), walkFn)
+}
+
+// WalkContext walks all the files provided
+func (c *Secure) WalkContext(ctx context.Context, walkFn WalkFunc) error {
+	return AsContextProvider(c.BackendProvider).WalkContext(ctx, walkFn)
 }
 
 // Retrieve file and verifies the signature
 func (c *Secure) Retrieve(src string, dest string) error {
-	err := c.BackendProvider.Retrieve(src, dest)
+	return c.RetrieveContext(context.Background(), src, dest)
+}
+
+// RetrieveContext retrieves file and verifies the signature
+func (c *Secure) RetrieveContext(ctx context.Context, src string, dest string) error {
+	err := AsContextProvider(c.BackendProvider).RetrieveContext(ctx, src, dest)
 	if err != nil {
 		return err
 	}
+	progress := Progress{Stage: StageVerify, Path: src, Total: 1}
+	ReportProgress(ctx, progress)
 	err = c.signatures.Verify(c.PublicKey, src, dest)
 	if err != nil {
+		LoggerFromContext(ctx).Error("signature verification failed", "path", src, "error", err)
 		os.Remove(dest)
 		return err
 	}
+	LoggerFromContext(ctx).Info("signature verified", "path", src)
+	progress.Current = 1
+	ReportProgress(ctx, progress)
 	return nil
 }
@@ -2,19 +2,34 @@ package provider
 
 import (
 	"archive/zip"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+
+	"github.com/mouuff/go-rocket-update/internal/constant"
+	"github.com/mouuff/go-rocket-update/pkg/version"
 )
 
 // Zip provider
 type Zip struct {
-	Path   string          // Path of the zip file (provider.GlobNewestFile might help)
-	reader *zip.ReadCloser // reader for the current zip file
+	Path            string           // Path of the zip file (provider.GlobNewestFile might help)
+	VersionComparer version.Comparer // (optional) Versioning scheme used to find the version in Path (the one of the context, like Updater.VersionComparer, by default)
+	reader          *zip.ReadCloser  // reader for the current zip file
 }
 
 // Open opens the provider
 func (c *Zip) Open() error {
+	return c.OpenContext(context.Background())
+}
+
+// OpenContext opens the provider
+func (c *Zip) OpenContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	_, err := os.Stat(c.Path)
 	if os.IsNotExist(err) {
 		return ErrProviderUnavailable
@@ -37,19 +52,59 @@ func (c *Zip) Close() error {
 
 // GetLatestVersion gets the latest version
 func (c *Zip) GetLatestVersion() (string, error) {
-	return GetLatestVersionFromPath(c.Path)
+	return c.GetLatestVersionContext(context.Background())
+}
+
+// GetLatestVersionContext gets the latest version
+func (c *Zip) GetLatestVersionContext(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return GetLatestVersionFromPathWithComparer(c.Path, comparerOrContext(ctx, c.VersionComparer))
+}
+
+// GetReleaseMetadataContext gets the metadata of the latest release from the file manifest.json of the zip (if it exists)
+func (c *Zip) GetReleaseMetadataContext(ctx context.Context) (*ReleaseMetadata, error) {
+	latestVersion, err := c.GetLatestVersionContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := zip.OpenReader(c.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	file, err := reader.Open(constant.ManifestRelPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &ReleaseMetadata{Version: latestVersion}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseManifest(file, latestVersion)
 }
 
 // Walk walks all the files provided
 func (c *Zip) Walk(walkFn WalkFunc) error {
+	return c.WalkContext(context.Background(), walkFn)
+}
+
+// WalkContext walks all the files provided
+func (c *Zip) WalkContext(ctx context.Context, walkFn WalkFunc) error {
 	if c.reader == nil {
 		return fmt.Errorf("nil zip.reader")
 	}
 	for _, f := range c.reader.File {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if f != nil {
+			LoggerFromContext(ctx).Debug("walking file", "archive", c.Path, "path", f.Name)
 			err := walkFn(&FileInfo{
 				Path: f.Name,
 				Mode: f.Mode(),
+				Size: int64(f.UncompressedSize64),
 			})
 			if err != nil {
 				return err
@@ -72,7 +127,15 @@ func (c *Zip) findFileByPath(path string) *zip.File {
 
 // Retrieve file relative to "provider" to destination
 func (c *Zip) Retrieve(src string, dest string) error {
+	return c.RetrieveContext(context.Background(), src, dest)
+}
 
+// RetrieveContext retrieves file relative to "provider" to destination
+// the progress is reported with StageExtract
+func (c *Zip) RetrieveContext(ctx context.Context, src string, dest string) error {
+	if c.reader == nil {
+		return fmt.Errorf("nil zip.reader")
+	}
 	zipFile := c.findFileByPath(src)
 	if zipFile == nil {
 		return ErrFileNotFound
@@ -82,6 +145,7 @@ func (c *Zip) Retrieve(src string, dest string) error {
 	if err != nil {
 		return err
 	}
+	defer inputFile.Close()
 
 	outputFile, err := os.OpenFile(
 		dest,
@@ -93,9 +157,15 @@ func (c *Zip) Retrieve(src string, dest string) error {
 	}
 	defer outputFile.Close()
 
-	_, err = io.Copy(outputFile, inputFile)
+	progress := Progress{Stage: StageExtract, Path: src, Total: int64(zipFile.UncompressedSize64)}
+	ReportProgress(ctx, progress)
+	_, err = io.Copy(outputFile, &contextReader{
+		ctx:    ctx,
+		reader: &progressReader{ctx: ctx, reader: inputFile, progress: progress},
+	})
 	if err != nil {
 		return err
 	}
+	LoggerFromContext(ctx).Debug("extracted file", "archive", c.Path, "path", src, "destination", dest)
 	return nil
 }
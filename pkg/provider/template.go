@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+)
+
+// ErrAmbiguousName is returned when more than one file matches a name
+var ErrAmbiguousName = errors.New("ambiguous name")
+
+// TemplateData is the data available in the templates of the names (example: Github.ArchiveName)
+// Example: "binaries_{{.Version}}_{{.GOOS}}.zip" or "{{.Name}}_{{.GOOS}}_{{.GOARCH}}{{.Ext}}"
+type TemplateData struct {
+	Name    string // Name of the project (the repository name for the providers, the executable name without extension for the updater)
+	Version string // Version being installed
+	GOOS    string // Same as runtime.GOOS
+	GOARCH  string // Same as runtime.GOARCH
+	Ext     string // Extension of the executables: ".exe" on windows, empty otherwise
+}
+
+// NewTemplateData creates the template data for the current platform
+func NewTemplateData(name, version string) *TemplateData {
+	ext := ""
+	if runtime.GOOS == "windows" {
+		ext = ".exe"
+	}
+	return &TemplateData{
+		Name:    name,
+		Version: version,
+		GOOS:    runtime.GOOS,
+		GOARCH:  runtime.GOARCH,
+		Ext:     ext,
+	}
+}
+
+// ExpandTemplate executes the template s using data (see text/template)
+// s is returned as is if it does not contain any action
+func ExpandTemplate(s string, data *TemplateData) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %q: %w", s, err)
+	}
+	var builder strings.Builder
+	if err = tmpl.Execute(&builder, data); err != nil {
+		return "", fmt.Errorf("could not execute template %q: %w", s, err)
+	}
+	return builder.String(), nil
+}
+
+// isGlob checks if pattern contains glob special characters (see filepath.Match)
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// MatchName checks if name matches pattern
+// pattern is either an exact name or a glob pattern (see filepath.Match)
+func MatchName(pattern, name string) (bool, error) {
+	if isGlob(pattern) {
+		return filepath.Match(pattern, name)
+	}
+	return pattern == name, nil
+}
+
+// FindName finds the only name matching pattern (see MatchName)
+// returns ErrFileNotFound if no name matches and ErrAmbiguousName if more than one name matches
+func FindName(pattern string, names []string) (string, error) {
+	var matches []string
+	for _, name := range names {
+		match, err := MatchName(pattern, name)
+		if err != nil {
+			return "", err
+		}
+		if match {
+			matches = append(matches, name)
+		}
+	}
+	if len(matches) < 1 {
+		return "", fmt.Errorf("%w: no match for %s", ErrFileNotFound, pattern)
+	}
+	if len(matches) > 1 {
+		return "", fmt.Errorf("%w: %s matches %s", ErrAmbiguousName, pattern, strings.Join(matches, ", "))
+	}
+	return matches[0], nil
+}
@@ -0,0 +1,74 @@
+package provider_test
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	data := provider.NewTemplateData("myapp", "v1.2.0")
+	ext := ""
+	if runtime.GOOS == "windows" {
+		ext = ".exe"
+	}
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{"binaries.zip", "binaries.zip"},
+		{"binaries_{{.Version}}_{{.GOOS}}.zip", "binaries_v1.2.0_" + runtime.GOOS + ".zip"},
+		{"{{.Name}}_{{.GOOS}}_{{.GOARCH}}{{.Ext}}", "myapp_" + runtime.GOOS + "_" + runtime.GOARCH + ext},
+	}
+	for _, test := range tests {
+		result, err := provider.ExpandTemplate(test.template, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != test.expected {
+			t.Errorf("ExpandTemplate(%s) = %s, expected %s", test.template, result, test.expected)
+		}
+	}
+
+	for _, bad := range []string{"{{.Unknown}}", "{{.Name"} {
+		if _, err := provider.ExpandTemplate(bad, data); err == nil {
+			t.Errorf("ExpandTemplate(%s) should return an error", bad)
+		}
+	}
+}
+
+func TestFindName(t *testing.T) {
+	names := []string{"myapp", "myapp-helper", "myapp.sha256"}
+
+	name, err := provider.FindName("myapp", names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "myapp" {
+		t.Errorf("FindName should match the exact name, got %s", name)
+	}
+
+	name, err = provider.FindName("*.sha256", names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "myapp.sha256" {
+		t.Errorf("FindName should match the glob pattern, got %s", name)
+	}
+
+	_, err = provider.FindName("myapp*", names)
+	if !errors.Is(err, provider.ErrAmbiguousName) {
+		t.Error("FindName should return ErrAmbiguousName when many names match")
+	}
+
+	_, err = provider.FindName("otherapp", names)
+	if !errors.Is(err, provider.ErrFileNotFound) {
+		t.Error("FindName should return ErrFileNotFound when no name matches")
+	}
+
+	if _, err = provider.MatchName("[", "myapp"); err == nil {
+		t.Error("MatchName should return an error on bad patterns")
+	}
+}
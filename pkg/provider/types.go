@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"context"
 	"errors"
 	"os"
 )
@@ -9,6 +10,7 @@ import (
 type FileInfo struct {
 	Path string
 	Mode os.FileMode
+	Size int64 // Size in bytes (uncompressed)
 }
 
 // WalkFunc is the type of the function called for each file or directory
@@ -31,6 +33,45 @@ type Provider interface {
 	Close() error
 }
 
+// A ContextProvider is a Provider which can be cancelled using a context.Context
+// AsContextProvider can be used to get a ContextProvider from any Provider
+type ContextProvider interface {
+	Provider
+	GetLatestVersionContext(ctx context.Context) (string, error)
+	OpenContext(ctx context.Context) error
+	WalkContext(ctx context.Context, walkFn WalkFunc) error
+	RetrieveContext(ctx context.Context, srcPath string, destPath string) error
+}
+
+// A DownloadSizer is a Provider which downloads its files when it is opened
+type DownloadSizer interface {
+	DownloadSize() int64 // Number of bytes downloaded by Open (-1 if unknown or not openned)
+}
+
+// DownloadSize gets the number of bytes downloaded by p when it was opened
+// returns -1 if p is not a DownloadSizer
+func DownloadSize(p Provider) int64 {
+	if adapter, ok := p.(*contextAdapter); ok {
+		p = adapter.Provider
+	}
+	if sizer, ok := p.(DownloadSizer); ok {
+		return sizer.DownloadSize()
+	}
+	return -1
+}
+
+// fileSize gets the size of the file at path (-1 if it does not exist)
+func fileSize(path string) int64 {
+	if path == "" {
+		return -1
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return -1
+	}
+	return info.Size()
+}
+
 var (
 	// ErrProviderUnavailable is a generic error when a provider is not available
 	ErrProviderUnavailable = errors.New("provider not available")
@@ -0,0 +1,145 @@
+package updater
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+	"github.com/mouuff/go-rocket-update/pkg/version"
+)
+
+// DefaultMaxBackups is the number of backups kept when BackupPolicy.MaxCount is not set
+const DefaultMaxBackups = 3
+
+// ErrBackupNotFound is returned by RollbackTo when there is no backup of the version
+var ErrBackupNotFound = fileio.ErrBackupNotFound
+
+// BackupPolicy configures the backups of the installed versions
+// Before an update, the installed executable is copied in Dir with the label Updater.Version
+type BackupPolicy struct {
+	Dir      string        // (optional) Directory of the backups (executable + ".backups" by default)
+	MaxCount int           // (optional) Maximum number of backups (DefaultMaxBackups by default, a negative value disables the backups)
+	MaxAge   time.Duration // (optional) Backups older than MaxAge are removed (they are kept forever by default)
+}
+
+// Backup describes a backup of a version of the executable
+type Backup struct {
+	Version string    // Version of the executable
+	Path    string    // Path of the copy of the executable
+	Time    time.Time // Time the backup was created
+}
+
+// getBackupStore gets the backup store of the executable
+func (u *Updater) getBackupStore() (*fileio.BackupStore, error) {
+	dir := u.BackupPolicy.Dir
+	if dir == "" {
+		executable, err := u.GetExecutable()
+		if err != nil {
+			return nil, err
+		}
+		dir = executable + ".backups"
+	}
+	return &fileio.BackupStore{Dir: dir}, nil
+}
+
+// backupExecutable copies the installed executable (labeled with Version) in the backup store
+// and removes the backups exceeding the policy
+func (u *Updater) backupExecutable() error {
+	maxCount := u.BackupPolicy.MaxCount
+	if maxCount < 0 {
+		return nil
+	}
+	if maxCount == 0 {
+		maxCount = DefaultMaxBackups
+	}
+	executable, err := u.GetExecutable()
+	if err != nil {
+		return err
+	}
+	store, err := u.getBackupStore()
+	if err != nil {
+		return err
+	}
+	backup, err := store.Add(executable, u.Version)
+	if err != nil {
+		return fmt.Errorf("could not backup executable: %w", err)
+	}
+	u.logger().Info("backup written", "path", store.Path(backup), "version", u.Version)
+	return store.Prune(maxCount, u.BackupPolicy.MaxAge)
+}
+
+// ListBackups lists the backups of the previously installed versions, the newest first
+func (u *Updater) ListBackups() ([]Backup, error) {
+	store, err := u.getBackupStore()
+	if err != nil {
+		return nil, err
+	}
+	backups, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Backup, 0, len(backups))
+	for _, backup := range backups {
+		result = append(result, Backup{
+			Version: backup.Version,
+			Path:    store.Path(&backup),
+			Time:    backup.Time,
+		})
+	}
+	return result, nil
+}
+
+// RollbackTo replaces the executable with the backup of targetVersion
+// versions are compared using VersionComparer, so "v1.0" matches the backup of "1.0.0"
+// The replaced executable can still be restored with Rollback()
+// It returns ErrUpdateInProgress if another process holds the update lock
+func (u *Updater) RollbackTo(targetVersion string) error {
+	lock, err := u.tryLock()
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		return fmt.Errorf("could not rollback: %w", ErrUpdateInProgress)
+	}
+	defer lock.Unlock()
+	return u.rollbackTo(targetVersion)
+}
+
+// rollbackTo is RollbackTo without the update lock
+func (u *Updater) rollbackTo(targetVersion string) error {
+	store, err := u.getBackupStore()
+	if err != nil {
+		return err
+	}
+	exact, err := store.Get(targetVersion)
+	if err == nil {
+		return u.installBackup(&Backup{Version: exact.Version, Path: store.Path(exact), Time: exact.Time})
+	}
+	if !errors.Is(err, ErrBackupNotFound) {
+		return err
+	}
+	backups, err := u.ListBackups()
+	if err != nil {
+		return err
+	}
+	comparer := version.OrDefault(u.VersionComparer)
+	for _, backup := range backups {
+		if cmp, err := comparer.Compare(backup.Version, targetVersion); err == nil && cmp == 0 {
+			return u.installBackup(&backup)
+		}
+	}
+	return fmt.Errorf("could not rollback: %w: %s", ErrBackupNotFound, targetVersion)
+}
+
+// installBackup replaces the executable with backup
+func (u *Updater) installBackup(backup *Backup) error {
+	patcher, err := u.getExecutablePatcher(backup.Path)
+	if err != nil {
+		return err
+	}
+	if err = patcher.Apply(); err != nil {
+		return fmt.Errorf("could not rollback to %s: %w", backup.Version, err)
+	}
+	return nil
+}
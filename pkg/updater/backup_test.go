@@ -0,0 +1,91 @@
+package updater_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+	"github.com/mouuff/go-rocket-update/pkg/updater"
+)
+
+func TestUpdaterBackups(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	executable := filepath.Join(tmpDir, "executable")
+	if err = os.WriteFile(executable, []byte("v1.0.0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	installed := "v1.0.0"
+	for _, next := range []string{"v1.1.0", "v1.2.0", "v1.3.0"} {
+		solution := filepath.Join(tmpDir, "solution-"+next+".zip")
+		if err = writeZipSolution(solution, map[string][]byte{"test": []byte(next)}); err != nil {
+			t.Fatal(err)
+		}
+		u := &updater.Updater{
+			Provider:           &provider.Zip{Path: solution},
+			ExecutableName:     "test",
+			Version:            installed,
+			OverrideExecutable: executable,
+			BackupPolicy:       updater.BackupPolicy{MaxCount: 2},
+		}
+		status, err := u.Update()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status != updater.Updated {
+			t.Fatal("status != updater.Updated")
+		}
+		installed = next
+	}
+
+	u := &updater.Updater{
+		Provider:           &provider.Local{Path: filepath.Join("testdata", "testSolution")},
+		ExecutableName:     "test",
+		Version:            installed,
+		OverrideExecutable: executable,
+	}
+	backups, err := u.ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("Only 2 backups should be kept, got %d", len(backups))
+	}
+	if backups[0].Version != "v1.2.0" || backups[1].Version != "v1.1.0" {
+		t.Errorf("Bad backups: %+v", backups)
+	}
+
+	if err = u.RollbackTo("1.1"); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(executable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "v1.1.0" {
+		t.Errorf("RollbackTo should install the backup of v1.1.0, got %s", content)
+	}
+
+	if err = u.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	content, err = os.ReadFile(executable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "v1.3.0" {
+		t.Errorf("Rollback should restore the executable replaced by RollbackTo, got %s", content)
+	}
+
+	if err = u.RollbackTo("v1.0.0"); !errors.Is(err, updater.ErrBackupNotFound) {
+		t.Errorf("RollbackTo should return ErrBackupNotFound for pruned versions, got %v", err)
+	}
+}
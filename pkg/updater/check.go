@@ -0,0 +1,116 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mouuff/go-rocket-update/pkg/provider"
+	"github.com/mouuff/go-rocket-update/pkg/version"
+)
+
+// UpdateCheck describes the latest release compared to the installed version
+type UpdateCheck struct {
+	CurrentVersion string                    // Version of the installed executable (Updater.Version)
+	LatestVersion  string                    // Latest version given by the provider
+	Available      bool                      // An update can be installed (same as CanUpdate)
+	Mandatory      bool                      // The update must be installed: the release is mandatory or Version is older than its MinSupportedVersion
+	Deferred       bool                      // A newer version exists but this installation is not part of its rollout yet
+	Excluded       bool                      // The latest version does not satisfy Constraint or is in SkipVersions
+	Metadata       *provider.ReleaseMetadata // Metadata of the latest release (see provider.MetadataProvider), only its Version is set if no update is available
+}
+
+// CheckForUpdate checks if an update is available and if it is mandatory
+// Apps can block their usage until a mandatory update is installed.
+// If the release has a rollout percentage, the update is only available to the installations
+// whose InstallationID is part of it (mandatory updates are always available)
+func (u *Updater) CheckForUpdate() (*UpdateCheck, error) {
+	return u.CheckForUpdateContext(context.Background())
+}
+
+// CheckForUpdateContext is the same as CheckForUpdate but the requests are cancelled when ctx is done
+func (u *Updater) CheckForUpdateContext(ctx context.Context) (*UpdateCheck, error) {
+	ctx, err := u.providerContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return u.checkForUpdate(ctx, true)
+}
+
+// checkForUpdate checks if an update is available, ctx must come from providerContext
+// The generated InstallationID is only saved if persist is true
+func (u *Updater) checkForUpdate(ctx context.Context, persist bool) (*UpdateCheck, error) {
+	latestVersion, err := u.GetLatestVersionContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	comparer := version.OrDefault(u.VersionComparer)
+	cmp, err := comparer.Compare(latestVersion, u.Version)
+	if err != nil {
+		return nil, fmt.Errorf("could not compare versions: %w", err)
+	}
+	check := &UpdateCheck{
+		CurrentVersion: u.Version,
+		LatestVersion:  latestVersion,
+		Metadata:       &provider.ReleaseMetadata{Version: latestVersion},
+	}
+	newer := cmp > 0 || (cmp < 0 && u.AllowDowngrade)
+	u.logger().Debug("versions compared", "current", u.Version, "latest", latestVersion, "newer", newer)
+	if !newer {
+		return check, nil
+	}
+	// Providers with a single release can't apply the version filter
+	accepted, err := u.acceptsVersion(latestVersion)
+	if err != nil {
+		return nil, err
+	}
+	if !accepted {
+		u.logger().Info("latest version excluded", "version", latestVersion, "constraint", u.Constraint)
+		check.Excluded = true
+		return check, nil
+	}
+	// The manifest is only downloaded when there is something to install
+	check.Metadata, err = u.getReleaseMetadata(ctx, latestVersion)
+	if err != nil {
+		return nil, fmt.Errorf("could not get release metadata: %w", err)
+	}
+
+	check.Mandatory = check.Metadata.Mandatory
+	if check.Metadata.MinSupportedVersion != "" {
+		cmp, err := comparer.Compare(u.Version, check.Metadata.MinSupportedVersion)
+		if err != nil {
+			return nil, fmt.Errorf("could not compare versions: %w", err)
+		}
+		if cmp < 0 {
+			check.Mandatory = true
+		}
+	}
+	if check.Mandatory {
+		u.logger().Warn("mandatory update", "current", u.Version, "latest", latestVersion,
+			"min_supported_version", check.Metadata.MinSupportedVersion)
+		check.Available = true
+		return check, nil
+	}
+
+	inRollout, err := u.isInRollout(latestVersion, check.Metadata.Rollout(), persist)
+	if err != nil {
+		return nil, err
+	}
+	check.Available = inRollout
+	check.Deferred = !inRollout
+	return check, nil
+}
+
+// getReleaseMetadata gets the metadata of the latest release (it is kept in cache for latestVersion)
+func (u *Updater) getReleaseMetadata(ctx context.Context, latestVersion string) (*provider.ReleaseMetadata, error) {
+	if u.releaseMetadata != nil && u.releaseMetadata.Version == latestVersion {
+		return u.releaseMetadata, nil
+	}
+	metadata, err := provider.GetReleaseMetadata(ctx, u.Provider)
+	if err != nil {
+		return nil, err
+	}
+	// The metadata must describe the version we are going to install
+	metadata.Version = latestVersion
+	u.releaseMetadata = metadata
+	return metadata, nil
+}
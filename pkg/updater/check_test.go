@@ -0,0 +1,123 @@
+package updater_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+	"github.com/mouuff/go-rocket-update/pkg/updater"
+)
+
+func TestUpdaterCheckForUpdate(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	if err = os.WriteFile(filepath.Join(tmpDir, "VERSION"), []byte("v1.3.0"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		manifest  string
+		version   string
+		available bool
+		mandatory bool
+	}{
+		{"", "v1.0.0", true, false},
+		{`{"mandatory": true}`, "v1.0.0", true, true},
+		{`{"mandatory": true}`, "v1.3.0", false, false},
+		{`{"min_supported_version": "v1.2.0"}`, "v1.1.9", true, true},
+		{`{"min_supported_version": "v1.2.0"}`, "v1.2.0", true, false},
+	}
+	for _, test := range tests {
+		manifestPath := filepath.Join(tmpDir, "manifest.json")
+		os.Remove(manifestPath)
+		if test.manifest != "" {
+			if err = os.WriteFile(manifestPath, []byte(test.manifest), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		u := &updater.Updater{
+			Provider:       &provider.Local{Path: tmpDir},
+			ExecutableName: "test",
+			Version:        test.version,
+		}
+		check, err := u.CheckForUpdate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if check.Available != test.available || check.Mandatory != test.mandatory {
+			t.Errorf("CheckForUpdate() = %+v with manifest %s and version %s", check, test.manifest, test.version)
+		}
+		if check.LatestVersion != "v1.3.0" || check.Metadata.Version != "v1.3.0" {
+			t.Errorf("Bad latest version: %+v", check)
+		}
+	}
+
+	if err = os.WriteFile(filepath.Join(tmpDir, "manifest.json"), []byte(`{"min_supported_version": "latest"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	u := &updater.Updater{
+		Provider:       &provider.Local{Path: tmpDir},
+		ExecutableName: "test",
+		Version:        "v1.0.0",
+	}
+	if _, err = u.CheckForUpdate(); err == nil {
+		t.Error("CheckForUpdate() should fail when min_supported_version is invalid")
+	}
+}
+
+func TestUpdaterCheckForUpdateRequests(t *testing.T) {
+	releasesRequests := 0
+	manifestRequests := 0
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/project/releases":
+			releasesRequests++
+			fmt.Fprintf(w, `[{"tag_name": "v1.3.0", "assets": [
+				{"name": "binaries.zip", "browser_download_url": "%[1]s/download/binaries.zip"},
+				{"name": "manifest.json", "browser_download_url": "%[1]s/download/manifest.json"}
+			]}]`, server.URL)
+		case "/download/manifest.json":
+			manifestRequests++
+			http.Error(w, "error", http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	newUpdater := func(version string) *updater.Updater {
+		return &updater.Updater{
+			Provider: &provider.Github{
+				RepositoryURL: "github.com/owner/project",
+				ArchiveName:   "binaries.zip",
+				APIURL:        server.URL,
+			},
+			ExecutableName: "test",
+			Version:        version,
+		}
+	}
+	canUpdate, err := newUpdater("v1.3.0").CanUpdate()
+	if err != nil {
+		t.Fatal("The manifest should not be needed when there is no update: ", err)
+	}
+	if canUpdate || releasesRequests != 1 || manifestRequests != 0 {
+		t.Errorf("Bad requests without update: %d releases, %d manifest", releasesRequests, manifestRequests)
+	}
+
+	releasesRequests = 0
+	if _, err = newUpdater("v1.0.0").CanUpdate(); err == nil {
+		t.Error("CanUpdate should fail when the manifest can't be downloaded")
+	}
+	if releasesRequests != 1 || manifestRequests != 1 {
+		t.Errorf("The releases should be listed once, got %d releases, %d manifest", releasesRequests, manifestRequests)
+	}
+}
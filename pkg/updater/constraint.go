@@ -0,0 +1,57 @@
+package updater
+
+import (
+	"fmt"
+
+	"github.com/mouuff/go-rocket-update/pkg/provider"
+	"github.com/mouuff/go-rocket-update/pkg/version"
+)
+
+// acceptsVersion checks if v satisfies Constraint and is not in SkipVersions
+// nor rolled back by the HealthCheck
+func (u *Updater) acceptsVersion(v string) (bool, error) {
+	rolledBack, err := u.rolledBackVersions()
+	if err != nil {
+		return false, err
+	}
+	return u.acceptsVersionSkipping(v, rolledBack)
+}
+
+// acceptsVersionSkipping checks if v satisfies Constraint and is neither in SkipVersions nor in skipped
+func (u *Updater) acceptsVersionSkipping(v string, skipped []string) (bool, error) {
+	comparer := version.OrDefault(u.VersionComparer)
+	for _, s := range append(append([]string{}, u.SkipVersions...), skipped...) {
+		if sameVersion(comparer, v, s) {
+			return false, nil
+		}
+	}
+	if u.Constraint == "" {
+		return true, nil
+	}
+	constraint, err := version.ParseConstraint(u.Constraint)
+	if err != nil {
+		return false, err
+	}
+	return constraint.Check(comparer, v)
+}
+
+// versionFilter gets the filter given to the providers (nil without Constraint, SkipVersions nor rolled back versions)
+// the versions which can't be compared are rejected
+func (u *Updater) versionFilter() (provider.VersionFilter, error) {
+	rolledBack, err := u.rolledBackVersions()
+	if err != nil {
+		return nil, err
+	}
+	if u.Constraint == "" && len(u.SkipVersions) == 0 && len(rolledBack) == 0 {
+		return nil, nil
+	}
+	if u.Constraint != "" {
+		if _, err := version.ParseConstraint(u.Constraint); err != nil {
+			return nil, fmt.Errorf("bad Constraint: %w", err)
+		}
+	}
+	return func(v string) bool {
+		accepted, err := u.acceptsVersionSkipping(v, rolledBack)
+		return err == nil && accepted
+	}, nil
+}
@@ -0,0 +1,134 @@
+package updater_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+	"github.com/mouuff/go-rocket-update/pkg/updater"
+)
+
+func TestUpdaterConstraint(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	archives := map[string][]byte{}
+	for _, v := range []string{"v3.0.0", "v2.3.1", "v2.3.0"} {
+		path := filepath.Join(tmpDir, v+".zip")
+		if err = writeZipSolution(path, map[string][]byte{"test": []byte(v)}); err != nil {
+			t.Fatal(err)
+		}
+		if archives[v], err = os.ReadFile(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/owner/project/releases" {
+			// two releases per page: the releases satisfying the constraint are on the second page
+			pages := [][]string{{"v3.0.0", "v2.3.1"}, {"v2.3.0", "v2.2.0"}}
+			page := 0
+			if r.URL.Query().Get("page") == "2" {
+				page = 1
+			} else {
+				w.Header().Set("Link", fmt.Sprintf(`<%s/repos/owner/project/releases?page=2>; rel="next", <%s/repos/owner/project/releases?page=2>; rel="last"`,
+					server.URL, server.URL))
+			}
+			var releases []string
+			for _, v := range pages[page] {
+				releases = append(releases, fmt.Sprintf(`{"tag_name": "%[1]s", "assets": [
+					{"name": "binaries.zip", "browser_download_url": "%[2]s/owner/project/releases/download/%[1]s/binaries.zip"}
+				]}`, v, server.URL))
+			}
+			fmt.Fprintf(w, "[%s]", strings.Join(releases, ","))
+			return
+		}
+		for v, archive := range archives {
+			if r.URL.Path == "/owner/project/releases/download/"+v+"/binaries.zip" {
+				w.Write(archive)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	executable := filepath.Join(tmpDir, "executable")
+	if err = os.WriteFile(executable, []byte("v2.2.0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	u := &updater.Updater{
+		Provider: &provider.Github{
+			RepositoryURL: "github.com/owner/project",
+			ArchiveName:   "binaries.zip",
+			APIURL:        server.URL,
+		},
+		ExecutableName:     "test",
+		Version:            "v2.2.0",
+		OverrideExecutable: executable,
+		Constraint:         "2.x",
+		SkipVersions:       []string{"2.3.1"},
+	}
+	latestVersion, err := u.GetLatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latestVersion != "v2.3.0" {
+		t.Errorf("The newest version satisfying the constraint should be v2.3.0, got %s", latestVersion)
+	}
+	status, err := u.Update()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != updater.Updated {
+		t.Fatal("status != updater.Updated")
+	}
+	content, err := os.ReadFile(executable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "v2.3.0" {
+		t.Errorf("v2.3.0 should be installed, got %s", content)
+	}
+
+	// Providers with a single version
+	solution := filepath.Join(tmpDir, "solution-v3.0.0.zip")
+	if err = writeZipSolution(solution, map[string][]byte{"test": []byte("v3.0.0")}); err != nil {
+		t.Fatal(err)
+	}
+	u = &updater.Updater{
+		Provider:           &provider.Zip{Path: solution},
+		ExecutableName:     "test",
+		Version:            "v2.3.0",
+		OverrideExecutable: executable,
+		Constraint:         ">=2.0 <3.0",
+	}
+	check, err := u.CheckForUpdate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check.Available || !check.Excluded {
+		t.Errorf("v3.0.0 should be excluded: %+v", check)
+	}
+	status, err = u.Update()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != updater.UpToDate {
+		t.Error("status should be UpToDate when the latest version is excluded")
+	}
+
+	u.Constraint = ">="
+	if _, err = u.CheckForUpdate(); err == nil {
+		t.Error("CheckForUpdate() should fail with an invalid constraint")
+	}
+}
@@ -0,0 +1,219 @@
+package updater
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+	"github.com/mouuff/go-rocket-update/pkg/version"
+)
+
+// ErrRolledBack is returned by Recover when an update which was never confirmed healthy has been rolled back
+// The previous version is installed: restart the program to run it (see Restart)
+var ErrRolledBack = errors.New("unconfirmed update rolled back")
+
+// HealthCheck requires the new version to call ConfirmHealthy after an update
+// If it is not confirmed within MaxLaunches launches or before Timeout, the update is rolled back
+// It is disabled when MaxLaunches and Timeout are not set
+type HealthCheck struct {
+	MaxLaunches int           // (optional) Number of launches of the new version (counted by Recover) allowed without confirmation
+	Timeout     time.Duration // (optional) Time allowed after the update to confirm the new version
+	StatePath   string        // (optional) File storing the update pending confirmation (executable + ".health" by default)
+}
+
+// enabled checks if the updates must be confirmed
+func (h *HealthCheck) enabled() bool {
+	return h.MaxLaunches > 0 || h.Timeout > 0
+}
+
+// pendingUpdate is an update waiting for ConfirmHealthy
+type pendingUpdate struct {
+	Version         string    `json:"version"`          // Installed version
+	PreviousVersion string    `json:"previous_version"` // Version restored by a rollback
+	Time            time.Time `json:"time"`             // Time of the update
+	Launches        int       `json:"launches"`         // Number of launches of the installed version
+}
+
+// healthState is saved at HealthCheck.StatePath
+type healthState struct {
+	Pending    *pendingUpdate `json:"pending,omitempty"`
+	RolledBack []string       `json:"rolled_back,omitempty"` // Versions rolled back automatically, they are not installed again
+}
+
+// getHealthStatePath gets the path of the health state file
+func (u *Updater) getHealthStatePath() (string, error) {
+	if u.HealthCheck.StatePath != "" {
+		return u.HealthCheck.StatePath, nil
+	}
+	executable, err := u.GetExecutable()
+	if err != nil {
+		return "", err
+	}
+	return executable + ".health", nil
+}
+
+// readHealthState reads the health state (an empty state if there is none)
+func (u *Updater) readHealthState() (*healthState, error) {
+	path, err := u.getHealthStatePath()
+	if err != nil {
+		return nil, err
+	}
+	state := &healthState{}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("invalid health state: %w", err)
+	}
+	return state, nil
+}
+
+// writeHealthState saves the health state, the file is removed when the state is empty
+func (u *Updater) writeHealthState(state *healthState) error {
+	path, err := u.getHealthStatePath()
+	if err != nil {
+		return err
+	}
+	if state.Pending == nil && len(state.RolledBack) == 0 {
+		if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fileio.WriteFileAtomic(path, content, 0644)
+}
+
+// rolledBackVersions gets the versions which were rolled back automatically
+func (u *Updater) rolledBackVersions() ([]string, error) {
+	if !u.HealthCheck.enabled() {
+		return nil, nil
+	}
+	state, err := u.readHealthState()
+	if err != nil {
+		return nil, err
+	}
+	return state.RolledBack, nil
+}
+
+// markPending records the update to latestVersion as pending confirmation
+func (u *Updater) markPending() error {
+	if !u.HealthCheck.enabled() {
+		return nil
+	}
+	state, err := u.readHealthState()
+	if err != nil {
+		return err
+	}
+	state.Pending = &pendingUpdate{
+		Version:         u.latestVersion,
+		PreviousVersion: u.Version,
+		Time:            time.Now(),
+	}
+	return u.writeHealthState(state)
+}
+
+// clearPending forgets the update pending confirmation
+func (u *Updater) clearPending() error {
+	state, err := u.readHealthState()
+	if err != nil {
+		return err
+	}
+	if state.Pending == nil {
+		return nil
+	}
+	state.Pending = nil
+	return u.writeHealthState(state)
+}
+
+// ConfirmHealthy confirms the installed version works, so it will not be rolled back
+// Call it once your program is known to work after an update (see HealthCheck)
+// It does nothing if no update is pending confirmation
+func (u *Updater) ConfirmHealthy() error {
+	state, err := u.readHealthState()
+	if err != nil {
+		return err
+	}
+	if state.Pending == nil {
+		return nil
+	}
+	u.logger().Info("update confirmed", "version", state.Pending.Version)
+	state.Pending = nil
+	return u.writeHealthState(state)
+}
+
+// RollbackIfUnconfirmed rolls back the update pending confirmation if HealthCheck.Timeout is exceeded
+// It can be called by a watchdog, Recover already calls it at startup
+// Nothing is done while another process holds the update lock
+func (u *Updater) RollbackIfUnconfirmed() (rolledBack bool, err error) {
+	lock, err := u.tryLock()
+	if err != nil || lock == nil {
+		return false, err
+	}
+	defer lock.Unlock()
+	return u.checkPending(false)
+}
+
+// checkPending rolls back the update pending confirmation if it exceeded the HealthCheck
+// launch counts a launch of the installed version, the update lock must be held
+func (u *Updater) checkPending(launch bool) (rolledBack bool, err error) {
+	if !u.HealthCheck.enabled() {
+		return false, nil
+	}
+	state, err := u.readHealthState()
+	if err != nil || state.Pending == nil {
+		return false, err
+	}
+	pending := state.Pending
+	if launch {
+		// The executable was replaced after the update (by a rollback or another installation)
+		if u.Version != "" && !sameVersion(u.VersionComparer, u.Version, pending.Version) {
+			u.logger().Info("pending update discarded", "version", pending.Version, "current", u.Version)
+			state.Pending = nil
+			return false, u.writeHealthState(state)
+		}
+		pending.Launches++
+	}
+	expired := u.HealthCheck.Timeout > 0 && time.Since(pending.Time) > u.HealthCheck.Timeout
+	exhausted := u.HealthCheck.MaxLaunches > 0 && pending.Launches > u.HealthCheck.MaxLaunches
+	if !expired && !exhausted {
+		return false, u.writeHealthState(state)
+	}
+	u.logger().Warn("update not confirmed, rolling back",
+		"version", pending.Version, "previous", pending.PreviousVersion, "launches", pending.Launches)
+	if err = u.rollbackPending(pending); err != nil {
+		return false, fmt.Errorf("could not rollback unconfirmed update: %w", err)
+	}
+	state.Pending = nil
+	state.RolledBack = append(state.RolledBack, pending.Version)
+	return true, u.writeHealthState(state)
+}
+
+// rollbackPending restores the version installed before the pending update
+// The backup of the patcher is used first, then the backup of PreviousVersion (see BackupPolicy)
+func (u *Updater) rollbackPending(pending *pendingUpdate) error {
+	err := u.Rollback()
+	if err == nil || !os.IsNotExist(err) {
+		return err
+	}
+	return u.rollbackTo(pending.PreviousVersion)
+}
+
+// sameVersion checks if a and b are the same version
+func sameVersion(comparer version.Comparer, a string, b string) bool {
+	if a == b {
+		return true
+	}
+	cmp, err := version.OrDefault(comparer).Compare(a, b)
+	return err == nil && cmp == 0
+}
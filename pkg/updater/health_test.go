@@ -0,0 +1,174 @@
+package updater_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+	"github.com/mouuff/go-rocket-update/pkg/updater"
+)
+
+// updateForHealthCheck installs v1.1.0 over the v1.0.0 executable of tmpDir
+func updateForHealthCheck(t *testing.T, tmpDir string, healthCheck updater.HealthCheck) (executable string, solution string) {
+	executable = filepath.Join(tmpDir, "executable")
+	if err := os.WriteFile(executable, []byte("v1.0.0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	solution = filepath.Join(tmpDir, "solution-v1.1.0.zip")
+	if err := writeZipSolution(solution, map[string][]byte{"test": []byte("v1.1.0")}); err != nil {
+		t.Fatal(err)
+	}
+	u := &updater.Updater{
+		Provider:           &provider.Zip{Path: solution},
+		ExecutableName:     "test",
+		Version:            "v1.0.0",
+		OverrideExecutable: executable,
+		HealthCheck:        healthCheck,
+	}
+	status, err := u.Update()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != updater.Updated {
+		t.Fatal("status != updater.Updated")
+	}
+	return
+}
+
+func checkExecutableContent(t *testing.T, executable string, expected string) {
+	content, err := os.ReadFile(executable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != expected {
+		t.Errorf("executable should be %s, got %s", expected, content)
+	}
+}
+
+func TestHealthCheckMaxLaunches(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	healthCheck := updater.HealthCheck{MaxLaunches: 1}
+	executable, solution := updateForHealthCheck(t, tmpDir, healthCheck)
+
+	u := &updater.Updater{
+		Provider:           &provider.Zip{Path: solution},
+		ExecutableName:     "test",
+		Version:            "v1.1.0",
+		OverrideExecutable: executable,
+		HealthCheck:        healthCheck,
+	}
+	if err = u.Recover(); err != nil {
+		t.Fatal(err)
+	}
+	checkExecutableContent(t, executable, "v1.1.0")
+
+	// The new version crashed before calling ConfirmHealthy
+	if err = u.Recover(); !errors.Is(err, updater.ErrRolledBack) {
+		t.Fatalf("Recover should return ErrRolledBack, got %v", err)
+	}
+	checkExecutableContent(t, executable, "v1.0.0")
+
+	u = &updater.Updater{
+		Provider:           &provider.Zip{Path: solution},
+		ExecutableName:     "test",
+		Version:            "v1.0.0",
+		OverrideExecutable: executable,
+		HealthCheck:        healthCheck,
+	}
+	if err = u.Recover(); err != nil {
+		t.Fatal(err)
+	}
+	canUpdate, err := u.CanUpdate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if canUpdate {
+		t.Error("The version rolled back should not be installed again")
+	}
+}
+
+func TestHealthCheckConfirmHealthy(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	healthCheck := updater.HealthCheck{MaxLaunches: 1}
+	executable, solution := updateForHealthCheck(t, tmpDir, healthCheck)
+	if !fileio.FileExists(executable + ".health") {
+		t.Fatal("The update should be pending confirmation")
+	}
+
+	u := &updater.Updater{
+		Provider:           &provider.Zip{Path: solution},
+		ExecutableName:     "test",
+		Version:            "v1.1.0",
+		OverrideExecutable: executable,
+		HealthCheck:        healthCheck,
+	}
+	if err = u.Recover(); err != nil {
+		t.Fatal(err)
+	}
+	if err = u.ConfirmHealthy(); err != nil {
+		t.Fatal(err)
+	}
+	if fileio.FileExists(executable + ".health") {
+		t.Error("ConfirmHealthy should remove the health state")
+	}
+	for i := 0; i < 3; i++ {
+		if err = u.Recover(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkExecutableContent(t, executable, "v1.1.0")
+}
+
+func TestHealthCheckTimeout(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	healthCheck := updater.HealthCheck{Timeout: time.Millisecond}
+	executable, solution := updateForHealthCheck(t, tmpDir, healthCheck)
+
+	u := &updater.Updater{
+		Provider:           &provider.Zip{Path: solution},
+		ExecutableName:     "test",
+		Version:            "v1.1.0",
+		OverrideExecutable: executable,
+		HealthCheck:        healthCheck,
+	}
+	// Without the ".old" file, the backup of v1.0.0 is installed
+	if err = u.CleanUp(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	rolledBack, err := u.RollbackIfUnconfirmed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rolledBack {
+		t.Fatal("The update should be rolled back after the timeout")
+	}
+	checkExecutableContent(t, executable, "v1.0.0")
+
+	rolledBack, err = u.RollbackIfUnconfirmed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rolledBack {
+		t.Error("The update should only be rolled back once")
+	}
+}
@@ -0,0 +1,87 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+)
+
+// ErrUpdateInProgress is returned when another process is updating the executable
+var ErrUpdateInProgress = errors.New("another update is in progress")
+
+// lockRetryInterval is the interval between two attempts to acquire the update lock
+const lockRetryInterval = 100 * time.Millisecond
+
+// getLockPath gets the path of the update lock
+func (u *Updater) getLockPath() (string, error) {
+	if u.LockPath != "" {
+		return u.LockPath, nil
+	}
+	executable, err := u.GetExecutable()
+	if err != nil {
+		return "", err
+	}
+	return executable + ".lock", nil
+}
+
+// lock acquires the update lock, waiting at most LockWait (forever if LockWait is negative)
+// returns ErrUpdateInProgress if the lock is still held by another process
+func (u *Updater) lock(ctx context.Context) (*fileio.FileLock, error) {
+	path, err := u.getLockPath()
+	if err != nil {
+		return nil, err
+	}
+	lock := &fileio.FileLock{Path: path}
+	deadline := time.Now().Add(u.LockWait)
+	for attempt := 0; ; {
+		locked, err := lock.TryLock()
+		if err != nil {
+			return nil, fmt.Errorf("could not lock %s: %w", path, err)
+		}
+		if locked {
+			return lock, nil
+		}
+		wait := lockRetryInterval
+		if u.LockWait >= 0 {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return nil, fmt.Errorf("%w: %s is locked", ErrUpdateInProgress, path)
+			}
+			if remaining < wait {
+				wait = remaining
+			}
+		}
+		if attempt == 0 {
+			u.logger().Info("waiting for another update", "lock", path)
+		}
+		attempt++
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// tryLock acquires the update lock without waiting
+// returns a nil lock if it is held by another process
+func (u *Updater) tryLock() (*fileio.FileLock, error) {
+	path, err := u.getLockPath()
+	if err != nil {
+		return nil, err
+	}
+	lock := &fileio.FileLock{Path: path}
+	locked, err := lock.TryLock()
+	if err != nil {
+		return nil, fmt.Errorf("could not lock %s: %w", path, err)
+	}
+	if !locked {
+		return nil, nil
+	}
+	return lock, nil
+}
@@ -0,0 +1,175 @@
+package updater_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+	"github.com/mouuff/go-rocket-update/pkg/updater"
+)
+
+func TestUpdaterLock(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	executable := filepath.Join(tmpDir, "executable")
+	if err = os.WriteFile(executable, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	solution := filepath.Join(tmpDir, "solution-v1.1.0.zip")
+	if err = writeZipSolution(solution, map[string][]byte{"test": []byte("new")}); err != nil {
+		t.Fatal(err)
+	}
+	newUpdater := func(lockWait time.Duration) *updater.Updater {
+		return &updater.Updater{
+			Provider:           &provider.Zip{Path: solution},
+			ExecutableName:     "test",
+			Version:            "v1.0.0",
+			OverrideExecutable: executable,
+			LockWait:           lockWait,
+		}
+	}
+
+	otherProcess := &fileio.FileLock{Path: executable + ".lock"}
+	locked, err := otherProcess.TryLock()
+	if err != nil || !locked {
+		t.Fatal("Could not acquire the lock: ", err)
+	}
+
+	status, err := newUpdater(0).Update()
+	if !errors.Is(err, updater.ErrUpdateInProgress) {
+		t.Errorf("Update() should return ErrUpdateInProgress, got %v", err)
+	}
+	if status != updater.Unknown {
+		t.Error("status should be Unknown when the lock is held")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = newUpdater(-1).UpdateContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("UpdateContext() should wait until ctx is done, got %v", err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		otherProcess.Unlock()
+	}()
+	status, err = newUpdater(10 * time.Second).Update()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != updater.Updated {
+		t.Error("Update() should update once the lock is released")
+	}
+	content, err := os.ReadFile(executable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "new" {
+		t.Errorf("The executable should be updated, got %s", content)
+	}
+}
+
+func TestUpdaterLockExecutableReplaced(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	executable := filepath.Join(tmpDir, "executable")
+	if err = os.WriteFile(executable, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	solution := filepath.Join(tmpDir, "solution-v1.1.0.zip")
+	if err = writeZipSolution(solution, map[string][]byte{"test": []byte("new")}); err != nil {
+		t.Fatal(err)
+	}
+
+	otherProcess := &fileio.FileLock{Path: executable + ".lock"}
+	if locked, err := otherProcess.TryLock(); err != nil || !locked {
+		t.Fatal("Could not acquire the lock: ", err)
+	}
+	go func() {
+		// The other process installs the new version while we wait
+		time.Sleep(50 * time.Millisecond)
+		os.WriteFile(executable+".new", []byte("installed by another process"), 0755)
+		os.Rename(executable+".new", executable)
+		otherProcess.Unlock()
+	}()
+
+	u := &updater.Updater{
+		Provider:           &provider.Zip{Path: solution},
+		ExecutableName:     "test",
+		Version:            "v1.0.0",
+		OverrideExecutable: executable,
+		LockWait:           10 * time.Second,
+	}
+	status, err := u.Update()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != updater.UpToDate {
+		t.Error("status should be UpToDate when another process installed the update")
+	}
+	content, err := os.ReadFile(executable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "installed by another process" {
+		t.Errorf("The executable should not be replaced twice, got %s", content)
+	}
+}
+
+func TestUpdaterRecoverLocked(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	executable := filepath.Join(tmpDir, "executable")
+	if err = os.WriteFile(executable, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// The journal of the update running in the other process
+	journal := []byte(`{"state": "started"}`)
+	if err = os.WriteFile(executable+".journal", journal, 0644); err != nil {
+		t.Fatal(err)
+	}
+	otherProcess := &fileio.FileLock{Path: executable + ".lock"}
+	if locked, err := otherProcess.TryLock(); err != nil || !locked {
+		t.Fatal("Could not acquire the lock: ", err)
+	}
+	defer otherProcess.Unlock()
+
+	u := &updater.Updater{
+		Provider:           &provider.Local{Path: tmpDir},
+		ExecutableName:     "test",
+		Version:            "v1.0.0",
+		OverrideExecutable: executable,
+		HealthCheck:        updater.HealthCheck{MaxLaunches: 1},
+	}
+	if err = u.Recover(); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(executable + ".journal")
+	if err != nil || string(content) != string(journal) {
+		t.Error("Recover() should not touch the update of another process")
+	}
+	if rolledBack, err := u.RollbackIfUnconfirmed(); err != nil || rolledBack {
+		t.Errorf("RollbackIfUnconfirmed() should do nothing while the lock is held, got %v, %v", rolledBack, err)
+	}
+	if err = u.RollbackTo("v0.9.0"); !errors.Is(err, updater.ErrUpdateInProgress) {
+		t.Errorf("RollbackTo() should return ErrUpdateInProgress, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package updater_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+	"github.com/mouuff/go-rocket-update/pkg/updater"
+)
+
+// recordingLogger is a Logger which records the messages
+type recordingLogger struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) record(msg string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.messages = append(l.messages, msg)
+}
+
+func (l *recordingLogger) Debug(msg string, args ...interface{}) { l.record(msg) }
+func (l *recordingLogger) Info(msg string, args ...interface{})  { l.record(msg) }
+func (l *recordingLogger) Warn(msg string, args ...interface{})  { l.record(msg) }
+func (l *recordingLogger) Error(msg string, args ...interface{}) { l.record(msg) }
+
+// logged checks if msg was recorded
+func (l *recordingLogger) logged(msg string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, m := range l.messages {
+		if m == msg {
+			return true
+		}
+	}
+	return false
+}
+
+func TestUpdaterLogger(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	executable := filepath.Join(tmpDir, "executable")
+	if err = os.WriteFile(executable, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	solution := filepath.Join(tmpDir, "solution-v1.1.0.zip")
+	if err = writeZipSolution(solution, map[string][]byte{"test": []byte("new")}); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := &recordingLogger{}
+	u := &updater.Updater{
+		Provider:           &provider.Zip{Path: solution},
+		ExecutableName:     "test",
+		Version:            "v1.0.0",
+		OverrideExecutable: executable,
+		Logger:             logger,
+	}
+	if _, err = u.Update(); err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range []string{"latest version", "walking file", "extracted file", "backup written", "file replaced", "updated"} {
+		if !logger.logged(msg) {
+			t.Errorf("%q should be logged", msg)
+		}
+	}
+
+	logger = &recordingLogger{}
+	u = &updater.Updater{
+		Provider:           &provider.Zip{Path: solution},
+		ExecutableName:     "doesnotexist",
+		Version:            "v1.0.0",
+		OverrideExecutable: executable,
+		Logger:             logger,
+	}
+	if _, err = u.Update(); err == nil {
+		t.Fatal("Update() should fail")
+	}
+	if !logger.logged("update failed") {
+		t.Errorf("The error should be logged: %v", logger.messages)
+	}
+}
@@ -0,0 +1,116 @@
+package updater
+
+import (
+	"context"
+
+	"github.com/mouuff/go-rocket-update/pkg/provider"
+)
+
+// SignatureStatus describes if the new executable is signed
+type SignatureStatus int
+
+const (
+	// SignatureUnchecked means the provider does not verify signatures (it is not a provider.Secure)
+	SignatureUnchecked SignatureStatus = iota
+	// SignatureFound means the executable has a signature, it will be verified when it is retrieved
+	SignatureFound
+	// SignatureMissing means the executable has no signature, the update will fail
+	SignatureMissing
+)
+
+// String gets the name of the status
+func (s SignatureStatus) String() string {
+	switch s {
+	case SignatureFound:
+		return "found"
+	case SignatureMissing:
+		return "missing"
+	default:
+		return "unchecked"
+	}
+}
+
+// UpdatePlan describes what Update() would do
+type UpdatePlan struct {
+	CurrentVersion string          // Version of the installed executable (Updater.Version)
+	LatestVersion  string          // Latest version given by the provider
+	CanUpdate      bool            // If false, Update() would not change anything and the next fields are empty
+	RemotePath     string          // Path of the new executable in the provider
+	ExecutablePath string          // Path of the executable which would be replaced
+	DownloadSize   int64           // Number of bytes downloaded by the provider (-1 if unknown, see provider.DownloadSizer)
+	ExecutableSize int64           // Size of the new executable
+	Signature      SignatureStatus // Signature of the new executable
+	RollbackPath   string          // Path of the copy of the installed executable used by Rollback()
+	BackupPath     string          // Path of the backup of CurrentVersion (empty if the backups are disabled, see BackupPolicy)
+}
+
+// Plan describes what Update() would do without changing the executable nor the backups
+// A generated InstallationID is not saved either, it is kept in memory for the next calls
+// The provider is opened (so the archives are downloaded to a temporary directory) and closed
+func (u *Updater) Plan() (*UpdatePlan, error) {
+	return u.PlanContext(context.Background())
+}
+
+// PlanContext is the same as Plan but it can be cancelled using ctx
+func (u *Updater) PlanContext(ctx context.Context) (*UpdatePlan, error) {
+	ctx, err := u.providerContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if u.ProgressFunc != nil {
+		ctx = provider.ContextWithProgress(ctx, newProgressThrottler(u.ProgressFunc, u.ProgressInterval).report)
+	}
+	check, err := u.checkForUpdate(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	canUpdate := check.Available
+	plan := &UpdatePlan{
+		CurrentVersion: u.Version,
+		LatestVersion:  u.latestVersion,
+		CanUpdate:      canUpdate,
+	}
+	if !canUpdate {
+		return plan, nil
+	}
+
+	p := provider.AsContextProvider(u.Provider)
+	if err = p.OpenContext(ctx); err != nil {
+		return nil, err
+	}
+	defer p.Close()
+
+	remote, err := u.findExecutableRemoteFile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plan.RemotePath = remote.Path
+	plan.ExecutableSize = remote.Size
+	plan.DownloadSize = provider.DownloadSize(u.Provider)
+
+	if secure, ok := u.Provider.(*provider.Secure); ok {
+		signed, err := secure.HasSignature(remote.Path)
+		if err != nil {
+			return nil, err
+		}
+		plan.Signature = SignatureMissing
+		if signed {
+			plan.Signature = SignatureFound
+		}
+	}
+
+	patcher, err := u.getExecutablePatcher("")
+	if err != nil {
+		return nil, err
+	}
+	plan.ExecutablePath = patcher.DestinationPath
+	plan.RollbackPath = patcher.BackupPath
+	if u.BackupPolicy.MaxCount >= 0 {
+		store, err := u.getBackupStore()
+		if err != nil {
+			return nil, err
+		}
+		plan.BackupPath = store.PathFor(patcher.DestinationPath, u.Version)
+	}
+	return plan, nil
+}
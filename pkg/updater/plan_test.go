@@ -0,0 +1,118 @@
+package updater_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+	"github.com/mouuff/go-rocket-update/pkg/updater"
+)
+
+func TestUpdaterPlan(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	executable := filepath.Join(tmpDir, "executable")
+	if err = os.WriteFile(executable, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	newExecutable := []byte("new executable")
+	solution := filepath.Join(tmpDir, "solution-v1.1.0.zip")
+	err = writeZipSolution(solution, map[string][]byte{
+		"test":            newExecutable,
+		"helper":          []byte("helper"),
+		"signatures.json": []byte(`{"Version": "1", "SignaturesMap": {"test": "c2lnbmF0dXJl"}}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u := &updater.Updater{
+		Provider:           &provider.Zip{Path: solution},
+		ExecutableName:     "test",
+		Version:            "v1.0.0",
+		OverrideExecutable: executable,
+	}
+	plan, err := u.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.CanUpdate || plan.CurrentVersion != "v1.0.0" || plan.LatestVersion != "v1.1.0" {
+		t.Errorf("Bad versions: %+v", plan)
+	}
+	if plan.RemotePath != "test" || plan.ExecutablePath != executable {
+		t.Errorf("Bad paths: %+v", plan)
+	}
+	if plan.ExecutableSize != int64(len(newExecutable)) {
+		t.Errorf("Bad executable size: %d", plan.ExecutableSize)
+	}
+	if plan.DownloadSize != -1 {
+		t.Errorf("The download size should be unknown for a zip provider: %d", plan.DownloadSize)
+	}
+	if plan.Signature != updater.SignatureUnchecked {
+		t.Errorf("Bad signature status: %s", plan.Signature)
+	}
+	if plan.RollbackPath != executable+".old" || filepath.Dir(plan.BackupPath) != executable+".backups" {
+		t.Errorf("Bad backup paths: %+v", plan)
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Plan() should not change the filesystem, got %d files", len(entries))
+	}
+	content, err := os.ReadFile(executable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "old" {
+		t.Error("Plan() should not change the executable")
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.Provider = &provider.Secure{
+		BackendProvider: &provider.Zip{Path: solution},
+		PublicKey:       &key.PublicKey,
+	}
+	u.BackupPolicy.MaxCount = -1
+	plan, err = u.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Signature != updater.SignatureFound {
+		t.Errorf("The signature of the executable should be found: %s", plan.Signature)
+	}
+	if plan.BackupPath != "" {
+		t.Errorf("No backup should be written when they are disabled: %s", plan.BackupPath)
+	}
+
+	u.ExecutableName = "helper"
+	plan, err = u.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Signature != updater.SignatureMissing {
+		t.Errorf("The signature of the helper should be missing: %s", plan.Signature)
+	}
+
+	u.Version = "v1.1.0"
+	plan, err = u.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.CanUpdate || plan.RemotePath != "" {
+		t.Errorf("Nothing should be planned when the executable is up to date: %+v", plan)
+	}
+}
@@ -0,0 +1,56 @@
+package updater
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mouuff/go-rocket-update/pkg/provider"
+)
+
+// DefaultProgressInterval is the minimum interval between two progress events
+// when Updater.ProgressInterval is not set
+const DefaultProgressInterval = 100 * time.Millisecond
+
+// progressThrottler limits the rate of the progress events
+// The first and the last event of each stage (and file) are always reported
+type progressThrottler struct {
+	progressFn provider.ProgressFunc
+	interval   time.Duration
+
+	mutex    sync.Mutex
+	last     provider.Progress
+	lastTime time.Time
+	hasLast  bool
+}
+
+// newProgressThrottler creates a throttled ProgressFunc
+func newProgressThrottler(progressFn provider.ProgressFunc, interval time.Duration) *progressThrottler {
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+	return &progressThrottler{
+		progressFn: progressFn,
+		interval:   interval,
+	}
+}
+
+// report reports the progress if the previous event is old enough
+func (t *progressThrottler) report(progress provider.Progress) {
+	t.mutex.Lock()
+	now := time.Now()
+	sameStep := t.hasLast && t.last.Stage == progress.Stage && t.last.Path == progress.Path
+	if sameStep && !progress.Done() && now.Sub(t.lastTime) < t.interval {
+		t.mutex.Unlock()
+		return
+	}
+	if sameStep && t.last.Done() && progress.Done() {
+		// Avoids reporting the end of a step twice
+		t.mutex.Unlock()
+		return
+	}
+	t.last = progress
+	t.lastTime = now
+	t.hasLast = true
+	t.mutex.Unlock()
+	t.progressFn(progress)
+}
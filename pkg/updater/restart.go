@@ -0,0 +1,28 @@
+package updater
+
+import (
+	"fmt"
+	"os"
+)
+
+// PreRestartFunc is called by Restart() before handing over to the new executable
+// Use it to flush the state of your program, returning an error aborts the restart
+type PreRestartFunc func(u *Updater) error
+
+// Restart restarts the program using the installed executable with the same arguments and environment
+// On Unix the current process is replaced (syscall.Exec), on other systems
+// the new executable is started and the current process exits.
+// Restart only returns if it fails.
+func (u *Updater) Restart() error {
+	executable, err := u.GetExecutable()
+	if err != nil {
+		return err
+	}
+	if u.PreRestartFunc != nil {
+		if err := u.PreRestartFunc(u); err != nil {
+			return fmt.Errorf("restart aborted: %w", err)
+		}
+	}
+	// The environment is read after PreRestartFunc so it can be modified
+	return restart(executable, os.Args, os.Environ())
+}
@@ -0,0 +1,63 @@
+package updater_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mouuff/go-rocket-update/pkg/updater"
+)
+
+const restartEnv = "ROCKET_UPDATE_TEST_RESTART"
+
+func TestRestart(t *testing.T) {
+	switch os.Getenv(restartEnv) {
+	case "restart":
+		executable, err := os.Executable()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		u := &updater.Updater{
+			OverrideExecutable: executable,
+			PreRestartFunc: func(u *updater.Updater) error {
+				return os.Setenv(restartEnv, "restarted")
+			},
+		}
+		fmt.Println(u.Restart())
+		os.Exit(1)
+	case "restarted":
+		fmt.Println("restarted")
+		os.Exit(0)
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestRestart$")
+	cmd.Env = append(os.Environ(), restartEnv+"=restart")
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err, string(output))
+	}
+	if !strings.Contains(string(output), "restarted") {
+		t.Error("The executable should have been restarted, output: " + string(output))
+	}
+}
+
+func TestRestartAborted(t *testing.T) {
+	errAbort := errors.New("abort")
+	u := &updater.Updater{
+		OverrideExecutable: "doesnotexists",
+		PreRestartFunc: func(u *updater.Updater) error {
+			return errAbort
+		},
+	}
+	if err := u.Restart(); !errors.Is(err, errAbort) {
+		t.Error("Restart() should return the error of PreRestartFunc")
+	}
+	u.PreRestartFunc = nil
+	if err := u.Restart(); err == nil {
+		t.Error("Restart() should fail if the executable does not exist")
+	}
+}
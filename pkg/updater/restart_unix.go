@@ -0,0 +1,13 @@
+//go:build aix || darwin || dragonfly || freebsd || illumos || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd illumos linux netbsd openbsd solaris
+
+package updater
+
+import (
+	"syscall"
+)
+
+// restart replaces the current process with executable
+func restart(executable string, args []string, env []string) error {
+	return syscall.Exec(executable, args, env)
+}
@@ -0,0 +1,74 @@
+package updater
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// getInstallationID gets InstallationID, it is generated and saved at InstallationIDPath on first use
+// The generated id is only kept in memory if persist is false (it is saved by the next call with persist)
+func (u *Updater) getInstallationID(persist bool) (string, error) {
+	if u.InstallationID != "" {
+		return u.InstallationID, nil
+	}
+	path := u.InstallationIDPath
+	if path == "" {
+		executable, err := u.GetExecutable()
+		if err != nil {
+			return "", err
+		}
+		path = executable + ".id"
+	}
+	content, err := os.ReadFile(path)
+	if err == nil && len(strings.TrimSpace(string(content))) > 0 {
+		u.InstallationID = strings.TrimSpace(string(content))
+		return u.InstallationID, nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("could not read installation id: %w", err)
+	}
+	if u.unsavedID == "" {
+		id := make([]byte, 16)
+		if _, err = rand.Read(id); err != nil {
+			return "", err
+		}
+		u.unsavedID = hex.EncodeToString(id)
+	}
+	if !persist {
+		return u.unsavedID, nil
+	}
+	if err = os.WriteFile(path, []byte(u.unsavedID), 0644); err != nil {
+		return "", fmt.Errorf("could not save installation id: %w", err)
+	}
+	u.InstallationID, u.unsavedID = u.unsavedID, ""
+	u.logger().Info("installation id generated", "path", path)
+	return u.InstallationID, nil
+}
+
+// rolloutBucket places the installation id in [0, 100) for the release latestVersion
+// The bucket of an installation does not change while the percentage of a release grows
+func rolloutBucket(id string, latestVersion string) float64 {
+	hash := sha256.Sum256([]byte(id + "\x00" + latestVersion))
+	return float64(binary.BigEndian.Uint64(hash[:8])%10000) / 100
+}
+
+// isInRollout checks if the installation receives the release latestVersion
+// The installation id is not saved if persist is false (see getInstallationID)
+func (u *Updater) isInRollout(latestVersion string, percentage float64, persist bool) (bool, error) {
+	if percentage >= 100 {
+		return true, nil
+	}
+	id, err := u.getInstallationID(persist)
+	if err != nil {
+		return false, err
+	}
+	bucket := rolloutBucket(id, latestVersion)
+	inRollout := bucket < percentage
+	u.logger().Debug("rollout", "version", latestVersion, "percentage", percentage, "bucket", bucket, "in_rollout", inRollout)
+	return inRollout, nil
+}
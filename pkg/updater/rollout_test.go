@@ -0,0 +1,142 @@
+package updater_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+	"github.com/mouuff/go-rocket-update/pkg/updater"
+)
+
+func TestUpdaterRollout(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	if err = os.WriteFile(filepath.Join(tmpDir, "VERSION"), []byte("v1.3.0"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	setManifest := func(manifest string) {
+		if err := os.WriteFile(filepath.Join(tmpDir, "manifest.json"), []byte(manifest), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	countAvailable := func(installations int) int {
+		available := 0
+		for i := 0; i < installations; i++ {
+			u := &updater.Updater{
+				Provider:       &provider.Local{Path: tmpDir},
+				ExecutableName: "test",
+				Version:        "v1.2.0",
+				InstallationID: fmt.Sprintf("installation-%d", i),
+			}
+			check, err := u.CheckForUpdate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if check.Available == check.Deferred {
+				t.Fatalf("An update is either available or deferred: %+v", check)
+			}
+			if check.Available {
+				available++
+			}
+		}
+		return available
+	}
+
+	setManifest(`{"rollout_percentage": 0}`)
+	if n := countAvailable(100); n != 0 {
+		t.Errorf("No installation should update with a rollout of 0%%, got %d", n)
+	}
+	setManifest(`{"rollout_percentage": 100}`)
+	if n := countAvailable(100); n != 100 {
+		t.Errorf("Every installation should update with a rollout of 100%%, got %d", n)
+	}
+	setManifest(`{"rollout_percentage": 25}`)
+	if n := countAvailable(1000); n < 150 || n > 350 {
+		t.Errorf("About 25%% of the installations should update, got %d/1000", n)
+	}
+	setManifest(`{"rollout_percentage": 0, "mandatory": true}`)
+	if n := countAvailable(100); n != 100 {
+		t.Errorf("Mandatory updates should ignore the rollout, got %d", n)
+	}
+
+	// The installations of a rollout stay in it when the percentage grows
+	inRollout := func(id string, percentage int) bool {
+		setManifest(fmt.Sprintf(`{"rollout_percentage": %d}`, percentage))
+		u := &updater.Updater{
+			Provider:       &provider.Local{Path: tmpDir},
+			ExecutableName: "test",
+			Version:        "v1.2.0",
+			InstallationID: id,
+		}
+		canUpdate, err := u.CanUpdate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return canUpdate
+	}
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("installation-%d", i)
+		if inRollout(id, 5) && !inRollout(id, 25) {
+			t.Errorf("%s should stay in the rollout from 5%% to 25%%", id)
+		}
+	}
+}
+
+func TestUpdaterInstallationID(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	if err = os.WriteFile(filepath.Join(tmpDir, "VERSION"), []byte("v1.3.0"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(tmpDir, "manifest.json"), []byte(`{"rollout_percentage": 50}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	executable := filepath.Join(tmpDir, "executable")
+
+	newUpdater := func() *updater.Updater {
+		return &updater.Updater{
+			Provider:           &provider.Local{Path: tmpDir},
+			ExecutableName:     "test",
+			Version:            "v1.2.0",
+			OverrideExecutable: executable,
+		}
+	}
+	if err = os.WriteFile(filepath.Join(tmpDir, "test"), []byte("new"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	u := newUpdater()
+	if _, err = u.Plan(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(executable + ".id"); !os.IsNotExist(err) {
+		t.Error("Plan() should not save the installation id")
+	}
+	if _, err = u.CheckForUpdate(); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(executable + ".id")
+	if err != nil {
+		t.Fatal("The installation id should be saved: ", err)
+	}
+	if u.InstallationID == "" || strings.TrimSpace(string(content)) != u.InstallationID {
+		t.Errorf("Bad installation id: %s (saved: %s)", u.InstallationID, content)
+	}
+
+	other := newUpdater()
+	if _, err = other.CheckForUpdate(); err != nil {
+		t.Fatal(err)
+	}
+	if other.InstallationID != u.InstallationID {
+		t.Error("The installation id should be the same on the next run")
+	}
+}
@@ -0,0 +1,155 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrSchedulerRunning is returned when Start() is called on a running scheduler
+	ErrSchedulerRunning = errors.New("scheduler is already running")
+	// ErrInvalidInterval is returned when the interval of a scheduler is not set
+	ErrInvalidInterval = errors.New("scheduler interval must be positive")
+)
+
+// Scheduler checks for updates periodically and installs them in background
+// Updates are run one at a time by a single goroutine.
+// Once an update have been installed the scheduler stops (the new version runs after a restart).
+// The Updater should not be used by another goroutine while the scheduler is running.
+type Scheduler struct {
+	Updater           *Updater
+	Interval          time.Duration        // Interval between two checks
+	Jitter            time.Duration        // (optional) Maximum random duration added to each delay (spreads the load on the provider)
+	RetryDelay        time.Duration        // (optional) Delay before retrying after a failure, doubled after each consecutive failure (1 minute or Interval if it is shorter by default)
+	MaxRetryDelay     time.Duration        // (optional) Maximum delay between two retries (Interval by default)
+	OnUpdateAvailable func(version string) // (optional) Called when a new version is found, before it is installed
+	OnUpdated         func(version string) // (optional) Called when a new version have been installed
+	OnError           func(err error)      // (optional) Called when a check or an update fails
+
+	mutex  sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Start starts checking for updates in background, the first check is done immediately
+func (s *Scheduler) Start() error {
+	if s.Interval <= 0 {
+		return ErrInvalidInterval
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.done != nil {
+		select {
+		case <-s.done:
+		default:
+			return ErrSchedulerRunning
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.run(ctx, s.done)
+	return nil
+}
+
+// Stop stops the scheduler and waits for the running update (if any) to be cancelled
+func (s *Scheduler) Stop() {
+	s.mutex.Lock()
+	cancel, done := s.cancel, s.done
+	s.mutex.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// run checks for updates until ctx is done or an update is installed
+func (s *Scheduler) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	failures := 0
+	for {
+		updated, err := s.check(ctx)
+		if ctx.Err() != nil || updated {
+			return
+		}
+		if err != nil {
+			failures++
+			if s.OnError != nil {
+				s.OnError(err)
+			}
+		} else {
+			failures = 0
+		}
+
+		timer := time.NewTimer(s.nextDelay(failures))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// check checks for an update and installs it
+// returns true if an update have been installed
+func (s *Scheduler) check(ctx context.Context) (bool, error) {
+	u := s.Updater
+	u.latestVersion = "" // the cached version and metadata might be outdated
+	u.releaseMetadata = nil
+	canUpdate, err := u.CanUpdateContext(ctx)
+	if err != nil || !canUpdate {
+		return false, err
+	}
+	latestVersion, err := u.GetLatestVersionContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	if s.OnUpdateAvailable != nil {
+		s.OnUpdateAvailable(latestVersion)
+	}
+	status, err := u.UpdateContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	if status != Updated {
+		return false, nil
+	}
+	if s.OnUpdated != nil {
+		s.OnUpdated(latestVersion)
+	}
+	return true, nil
+}
+
+// nextDelay computes the delay before the next check
+// the delay grows exponentially with the number of consecutive failures
+func (s *Scheduler) nextDelay(failures int) time.Duration {
+	delay := s.Interval
+	if failures > 0 {
+		maxDelay := s.MaxRetryDelay
+		if maxDelay <= 0 {
+			maxDelay = s.Interval
+		}
+		delay = s.RetryDelay
+		if delay <= 0 {
+			delay = time.Minute
+			if s.Interval < delay {
+				delay = s.Interval
+			}
+		}
+		for i := 1; i < failures && delay < maxDelay; i++ {
+			delay *= 2
+		}
+		if delay > maxDelay {
+			delay = maxDelay
+		}
+	}
+	if s.Jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(s.Jitter) + 1))
+	}
+	return delay
+}
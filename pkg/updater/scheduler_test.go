@@ -0,0 +1,116 @@
+package updater_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+	"github.com/mouuff/go-rocket-update/pkg/updater"
+)
+
+func TestScheduler(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	executable := filepath.Join(tmpDir, "executable")
+	if err = os.WriteFile(executable, []byte("old executable"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	solution := filepath.Join(tmpDir, "solution-v1.1.0.zip")
+	if err = writeZipSolution(solution, map[string][]byte{"test": []byte("new executable")}); err != nil {
+		t.Fatal(err)
+	}
+
+	available := make(chan string, 1)
+	updated := make(chan string, 1)
+	s := &updater.Scheduler{
+		Updater: &updater.Updater{
+			Provider:           &provider.Zip{Path: solution},
+			ExecutableName:     "test",
+			Version:            "v1.0.0",
+			OverrideExecutable: executable,
+		},
+		Interval:          time.Hour,
+		OnUpdateAvailable: func(version string) { available <- version },
+		OnUpdated:         func(version string) { updated <- version },
+		OnError:           func(err error) { t.Error(err) },
+	}
+	if err = s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	select {
+	case version := <-available:
+		if version != "v1.1.0" {
+			t.Error("OnUpdateAvailable called with " + version)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnUpdateAvailable should be called")
+	}
+	select {
+	case version := <-updated:
+		if version != "v1.1.0" {
+			t.Error("OnUpdated called with " + version)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnUpdated should be called")
+	}
+	s.Stop()
+	if err = s.Start(); err != nil {
+		t.Error("Should be able to restart a stopped scheduler")
+	}
+}
+
+func TestSchedulerBackoff(t *testing.T) {
+	var mutex sync.Mutex
+	var errorTimes []time.Time
+	s := &updater.Scheduler{
+		Updater: &updater.Updater{
+			Provider:       &provider.Local{Path: filepath.Join("testdata", "doesnotexists")},
+			ExecutableName: "test",
+			Version:        "v1.0.0",
+		},
+		Interval:      time.Hour,
+		RetryDelay:    10 * time.Millisecond,
+		MaxRetryDelay: 40 * time.Millisecond,
+		OnError: func(err error) {
+			mutex.Lock()
+			errorTimes = append(errorTimes, time.Now())
+			mutex.Unlock()
+		},
+	}
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Start(); err != updater.ErrSchedulerRunning {
+		t.Error("Start() should return ErrSchedulerRunning when already running")
+	}
+	time.Sleep(300 * time.Millisecond)
+	s.Stop()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(errorTimes) < 4 {
+		t.Fatalf("Should have retried at least 4 times, retried %d times", len(errorTimes))
+	}
+	if errorTimes[2].Sub(errorTimes[1]) < 20*time.Millisecond {
+		t.Error("The retry delay should double after each failure")
+	}
+	count := len(errorTimes)
+	time.Sleep(100 * time.Millisecond)
+	if len(errorTimes) != count {
+		t.Error("Should not check for updates after Stop()")
+	}
+
+	if err := (&updater.Scheduler{}).Start(); err != updater.ErrInvalidInterval {
+		t.Error("Start() should return ErrInvalidInterval without interval")
+	}
+}
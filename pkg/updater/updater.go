@@ -1,13 +1,17 @@
 package updater
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"time"
 
 	"github.com/mouuff/go-rocket-update/internal/fileio"
 	"github.com/mouuff/go-rocket-update/pkg/provider"
+	"github.com/mouuff/go-rocket-update/pkg/version"
 )
 
 // UpdateStatus represents the status after Updater{}.Update() was called
@@ -29,11 +33,28 @@ type PostUpdateFunc func(u *Updater) (UpdateStatus, error)
 // Updater struct
 type Updater struct {
 	Provider           provider.Provider
-	ExecutableName     string         // Name of the executable
-	Version            string         // The current version of your program
-	OverrideExecutable string         // (optional) Overrides the path of the executable
-	PostUpdateFunc     PostUpdateFunc // (optional) Set a function that will be called after an update (see type documentation)
-	latestVersion      string         // cache for the latest version
+	ExecutableName     string                    // Name of the executable (".exe" is optional), it can be a template (see provider.TemplateData) or a glob pattern
+	Version            string                    // The current version of your program
+	OverrideExecutable string                    // (optional) Overrides the path of the executable
+	PostUpdateFunc     PostUpdateFunc            // (optional) Set a function that will be called after an update (see type documentation)
+	AllowDowngrade     bool                      // (optional) Allows to install a latest version which is older than Version
+	VersionComparer    version.Comparer          // (optional) Versioning scheme of Version (semver by default, see package version)
+	ProgressFunc       provider.ProgressFunc     // (optional) Called to report the progress of the download, extraction, verification and patch
+	ProgressInterval   time.Duration             // (optional) Minimum interval between two progress events of the same step (DefaultProgressInterval by default)
+	PreRestartFunc     PreRestartFunc            // (optional) Called by Restart() before handing over to the new executable
+	ValidateFunc       ValidateFunc              // (optional) Validates the downloaded executable before installing it (see CommandValidator)
+	BackupPolicy       BackupPolicy              // (optional) Configures the backups of the previous versions (see ListBackups and RollbackTo)
+	LockPath           string                    // (optional) Lock shared by the processes updating the executable (executable + ".lock" by default)
+	LockWait           time.Duration             // (optional) How long Update waits for another process to finish its update, it returns ErrUpdateInProgress immediately by default (negative to wait forever)
+	Logger             provider.Logger           // (optional) Records the steps of the updates, including the ones of the provider (a *slog.Logger can be used)
+	InstallationID     string                    // (optional) Identifies this installation for the staged rollouts (generated and saved at InstallationIDPath by default)
+	InstallationIDPath string                    // (optional) File storing the generated InstallationID (executable + ".id" by default)
+	Constraint         string                    // (optional) Versions which can be installed, example: ">=2.0 <3.0" or "2.x" (see version.Constraint)
+	SkipVersions       []string                  // (optional) Versions which must never be installed, example: a known bad release
+	HealthCheck        HealthCheck               // (optional) Rolls back the updates which are not confirmed by the new version (see ConfirmHealthy)
+	latestVersion      string                    // cache for the latest version
+	releaseMetadata    *provider.ReleaseMetadata // cache for the metadata of the latest version
+	unsavedID          string                    // InstallationID generated by Plan() which is not saved yet
 }
 
 // getExecutablePatcher gets the executable patcher
@@ -48,47 +69,141 @@ func (u *Updater) getExecutablePatcher(executableCandidatePath string) (*fileio.
 		SourcePath:      executableCandidatePath,
 		DestinationPath: executable,
 		BackupPath:      executable + ".old",
-		Mode:            0755,
+		JournalPath:     executable + ".journal",
+		Logger:          u.logger(),
 	}, nil
 }
 
-// findExecutableRemotePath finds the remote executable path using the provider
-func (u *Updater) findExecutableRemotePath() (string, error) {
-	executableRemotePath := ""
-	err := u.Provider.Walk(func(info *provider.FileInfo) error {
-		if info.Mode.IsRegular() && strings.Contains(filepath.Base(info.Path), u.ExecutableName) {
-			executableRemotePath = info.Path
+// getExecutableNamePattern expands the template of ExecutableName
+func (u *Updater) getExecutableNamePattern(ctx context.Context) (string, error) {
+	latestVersion, err := u.GetLatestVersionContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	name := ""
+	if executable, err := u.GetExecutable(); err == nil {
+		name = strings.TrimSuffix(filepath.Base(executable), ".exe")
+	}
+	return provider.ExpandTemplate(u.ExecutableName, provider.NewTemplateData(name, latestVersion))
+}
+
+// findExecutableRemoteFile finds the remote executable using the provider
+// The file name must match ExecutableName exactly (the ".exe" extension is optional) or as a glob pattern
+// When both "name" and "name.exe" exist, "name.exe" is only used on windows
+func (u *Updater) findExecutableRemoteFile(ctx context.Context) (*provider.FileInfo, error) {
+	pattern, err := u.getExecutableNamePattern(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var matches, exeMatches []*provider.FileInfo
+	err = provider.AsContextProvider(u.Provider).WalkContext(ctx, func(info *provider.FileInfo) error {
+		if !info.Mode.IsRegular() {
+			return nil
+		}
+		name := filepath.Base(info.Path)
+		match, err := provider.MatchName(pattern, name)
+		if err != nil {
+			return err
+		}
+		if match {
+			matches = append(matches, info)
+		} else if name == pattern+".exe" {
+			exeMatches = append(exeMatches, info)
 		}
 		return nil
 	})
 	if err != nil {
-		return "", fmt.Errorf("could not find excutable remote path: %w", err)
+		return nil, fmt.Errorf("could not find excutable remote path: %w", err)
 	}
-	return executableRemotePath, nil
+	// pattern + ".exe" is preferred on windows, elsewhere it is only used without an exact match
+	files := matches
+	if len(exeMatches) > 0 && (runtime.GOOS == "windows" || len(matches) == 0) {
+		files = exeMatches
+	}
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	for _, file := range files {
+		u.logger().Debug("remote executable candidate", "path", file.Path, "pattern", pattern)
+	}
+	if len(files) < 1 {
+		return nil, fmt.Errorf("could not find excutable remote path: %w: no match for %s", provider.ErrFileNotFound, pattern)
+	}
+	if len(files) > 1 {
+		return nil, fmt.Errorf("could not find excutable remote path: %w: %s matches %s",
+			provider.ErrAmbiguousName, pattern, strings.Join(paths, ", "))
+	}
+	return files[0], nil
 }
 
 // updateExecutable updates the current executable with the new one
-func (u *Updater) updateExecutable() (err error) {
+func (u *Updater) updateExecutable(ctx context.Context) (err error) {
 	tmpDir, err := fileio.TempDir()
 	if err != nil {
 		return
 	}
 	defer os.RemoveAll(tmpDir)
-	executableRemotePath, err := u.findExecutableRemotePath()
+	executableRemote, err := u.findExecutableRemoteFile(ctx)
 	if err != nil {
 		return
 	}
+	executableRemotePath := executableRemote.Path
 	executableCanditatePath := filepath.Join(tmpDir, filepath.Base(executableRemotePath))
-	err = u.Provider.Retrieve(executableRemotePath, executableCanditatePath)
+	err = provider.AsContextProvider(u.Provider).RetrieveContext(ctx, executableRemotePath, executableCanditatePath)
 	if err != nil {
 		return
 	}
+	if u.ValidateFunc != nil {
+		if err = u.ValidateFunc(ctx, u, executableCanditatePath); err != nil {
+			u.logger().Error("executable validation failed", "path", executableRemotePath, "error", err)
+			return
+		}
+		u.logger().Info("executable validated", "path", executableRemotePath)
+	}
+	// Last chance to cancel: the patch itself is not interrupted
+	if err = ctx.Err(); err != nil {
+		return
+	}
 
 	patcher, err := u.getExecutablePatcher(executableCanditatePath)
 	if err != nil {
 		return
 	}
-	return patcher.Apply() // on failure it will automatically rollback already
+	if err = u.backupExecutable(); err != nil {
+		return
+	}
+	progress := provider.Progress{Stage: provider.StagePatch, Path: patcher.DestinationPath, Total: 1}
+	provider.ReportProgress(ctx, progress)
+	err = patcher.Apply() // on failure it will automatically rollback already
+	if err != nil {
+		return
+	}
+	progress.Current = 1
+	provider.ReportProgress(ctx, progress)
+	return nil
+}
+
+// logger gets the Logger of the updater
+func (u *Updater) logger() provider.Logger {
+	if u.Logger == nil {
+		return provider.LoggerFromContext(context.Background())
+	}
+	return u.Logger
+}
+
+// providerContext returns a copy of ctx which carries the logger and the version filter given to the provider
+func (u *Updater) providerContext(ctx context.Context) (context.Context, error) {
+	filter, err := u.versionFilter()
+	if err != nil {
+		return nil, err
+	}
+	ctx = provider.ContextWithLogger(ctx, u.logger())
+	ctx = provider.ContextWithVersionComparer(ctx, u.VersionComparer)
+	if filter != nil {
+		ctx = provider.ContextWithVersionFilter(ctx, filter)
+	}
+	return ctx, nil
 }
 
 // GetExecutable gets the executable path that will be used to for the update process
@@ -102,28 +217,43 @@ func (u *Updater) GetExecutable() (string, error) {
 
 // GetLatestVersion gets the latest version (same as provider.GetLatestVersion but keeps the version in cache)
 func (u *Updater) GetLatestVersion() (string, error) {
+	return u.GetLatestVersionContext(context.Background())
+}
+
+// GetLatestVersionContext is the same as GetLatestVersion but the request is cancelled when ctx is done
+func (u *Updater) GetLatestVersionContext(ctx context.Context) (string, error) {
 	if u.latestVersion != "" {
 		return u.latestVersion, nil
 	}
-	var err error
-	u.latestVersion, err = u.Provider.GetLatestVersion()
+	ctx, err := u.providerContext(ctx)
 	if err != nil {
+		return "", err
+	}
+	u.latestVersion, err = provider.AsContextProvider(u.Provider).GetLatestVersionContext(ctx)
+	if err != nil {
+		u.logger().Error("could not get the latest version", "error", err)
 		u.latestVersion = ""
 		return u.latestVersion, err
 	}
+	u.logger().Info("latest version", "version", u.latestVersion)
 	return u.latestVersion, nil
 }
 
-// CanUpdate checks if the updater found a new version
+// CanUpdate checks if the updater found a newer version
+// versions are compared using VersionComparer (semantic versions by default)
+// an older version is only accepted if AllowDowngrade is set
+// and the installation must be part of the rollout of the release (see CheckForUpdate)
 func (u *Updater) CanUpdate() (bool, error) {
-	latestVersion, err := u.GetLatestVersion()
+	return u.CanUpdateContext(context.Background())
+}
+
+// CanUpdateContext is the same as CanUpdate but the request is cancelled when ctx is done
+func (u *Updater) CanUpdateContext(ctx context.Context) (bool, error) {
+	check, err := u.CheckForUpdateContext(ctx)
 	if err != nil {
 		return false, err
 	}
-	if u.Version != latestVersion {
-		return true, nil
-	}
-	return false, nil
+	return check.Available, nil
 }
 
 // Update runs the updater
@@ -132,8 +262,26 @@ func (u *Updater) CanUpdate() (bool, error) {
 // YOU DON'T NEED TO call Rollback() yourself!
 // UNLESS you want to rollback after a successful update
 func (u *Updater) Update() (status UpdateStatus, err error) {
+	return u.UpdateContext(context.Background())
+}
+
+// UpdateContext is the same as Update but it can be cancelled using ctx
+// The downloads are cancelled and the temporary files are removed when ctx is done.
+// Once the executable starts being replaced, it is not interrupted anymore.
+func (u *Updater) UpdateContext(ctx context.Context) (status UpdateStatus, err error) {
 	status = Unknown
-	canUpdate, err := u.CanUpdate()
+	if ctx, err = u.providerContext(ctx); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			u.logger().Error("update failed", "version", u.Version, "error", err)
+		}
+	}()
+	if u.ProgressFunc != nil {
+		ctx = provider.ContextWithProgress(ctx, newProgressThrottler(u.ProgressFunc, u.ProgressInterval).report)
+	}
+	canUpdate, err := u.CanUpdateContext(ctx)
 	if err != nil {
 		return
 	}
@@ -141,18 +289,50 @@ func (u *Updater) Update() (status UpdateStatus, err error) {
 		status = UpToDate
 		return
 	}
-	if err = u.Provider.Open(); err != nil {
+	executable, err := u.GetExecutable()
+	if err != nil {
+		return
+	}
+	executableInfo, err := os.Stat(executable)
+	if err != nil {
+		return
+	}
+	lock, err := u.lock(ctx)
+	if err != nil {
+		return
+	}
+	defer lock.Unlock()
+	// The executable was replaced by the process which held the lock
+	if info, err := os.Stat(executable); err == nil && !os.SameFile(info, executableInfo) {
+		u.logger().Info("executable updated by another process", "path", executable)
+		status = UpToDate
+		return status, nil
+	}
+	u.logger().Info("updating", "path", executable, "current", u.Version, "latest", u.latestVersion)
+
+	p := provider.AsContextProvider(u.Provider)
+	if err = p.OpenContext(ctx); err != nil {
 		return
 	}
-	defer u.Provider.Close()
+	defer p.Close()
 
-	err = u.updateExecutable()
+	err = u.updateExecutable(ctx)
 	if err == nil {
 		status = Updated
+		u.logger().Info("updated", "path", executable, "version", u.latestVersion)
 	}
 	// WARNING: any code after that should also call Rollback() on failure
+	if status == Updated {
+		if err = u.markPending(); err != nil {
+			u.Rollback()
+			return Unknown, fmt.Errorf("could not save pending update: %w", err)
+		}
+	}
 	if status == Updated && u.PostUpdateFunc != nil {
 		status, err = u.PostUpdateFunc(u) // That's up to the PostUpdateFunc to rollback or not here
+		if status != Updated {
+			u.clearPending()
+		}
 	}
 	return
 }
@@ -177,3 +357,36 @@ func (u *Updater) CleanUp() (err error) {
 
 	return executablePatcher.CleanUp()
 }
+
+// Recover completes or reverts an update interrupted by a crash (or a power loss)
+// Call it at the start of your program, before checking for updates.
+// It does nothing if no update was interrupted
+// With a HealthCheck, it also counts the launches of an update pending confirmation and returns ErrRolledBack
+// if it was rolled back (restart your program to run the previous version)
+// Nothing is done while another process holds the update lock: its update is not interrupted
+func (u *Updater) Recover() error {
+	lock, err := u.tryLock()
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		u.logger().Info("update in progress in another process, recovery skipped")
+		return nil
+	}
+	defer lock.Unlock()
+	executablePatcher, err := u.getExecutablePatcher("")
+	if err != nil {
+		return err
+	}
+	if err = executablePatcher.Recover(); err != nil {
+		return fmt.Errorf("could not recover update: %w", err)
+	}
+	rolledBack, err := u.checkPending(true)
+	if err != nil {
+		return err
+	}
+	if rolledBack {
+		return ErrRolledBack
+	}
+	return nil
+}
@@ -1,18 +1,47 @@
 package updater_test
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/mouuff/go-rocket-update/internal/crypto"
 	"github.com/mouuff/go-rocket-update/internal/fileio"
 	provider "github.com/mouuff/go-rocket-update/pkg/provider"
 	"github.com/mouuff/go-rocket-update/pkg/updater"
+	"github.com/mouuff/go-rocket-update/pkg/version"
 )
 
+// writeZipSolution creates a zip file containing the given files
+func writeZipSolution(path string, files map[string][]byte) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	zipWriter := zip.NewWriter(file)
+	for name, content := range files {
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+		header.SetMode(0755)
+		w, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write(content); err != nil {
+			return err
+		}
+	}
+	return zipWriter.Close()
+}
+
 func TestUpdater(t *testing.T) {
 	tmpDir, err := fileio.TempDir()
 	if err != nil {
@@ -123,7 +152,7 @@ func TestUpdaterCleanUp(t *testing.T) {
 	u := &updater.Updater{
 		Provider:           &provider.Local{Path: solutionDir},
 		ExecutableName:     "test",
-		Version:            "v1.1",
+		Version:            "v0.9",
 		OverrideExecutable: executable,
 	}
 
@@ -154,6 +183,102 @@ func TestUpdaterCleanUp(t *testing.T) {
 	}
 }
 
+func TestUpdaterCanUpdate(t *testing.T) {
+	solutionDir := filepath.Join("testdata", "testSolution") // VERSION is v1.0
+	tests := []struct {
+		version        string
+		allowDowngrade bool
+		expected       bool
+	}{
+		{"v0.9", false, true},
+		{"v1.0.0-rc.1", false, true},
+		{"v1.0", false, false},
+		{"1.0.0", false, false},
+		{"v1.1", false, false},
+		{"v1.1", true, true},
+		{"v1.0", true, false},
+	}
+	for _, test := range tests {
+		u := &updater.Updater{
+			Provider:       &provider.Local{Path: solutionDir},
+			ExecutableName: "test",
+			Version:        test.version,
+			AllowDowngrade: test.allowDowngrade,
+		}
+		canUpdate, err := u.CanUpdate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if canUpdate != test.expected {
+			t.Errorf("CanUpdate() = %v with version %s (AllowDowngrade: %v)", canUpdate, test.version, test.allowDowngrade)
+		}
+	}
+
+	u := &updater.Updater{
+		Provider:       &provider.Local{Path: solutionDir},
+		ExecutableName: "test",
+		Version:        "not a version",
+	}
+	if _, err := u.CanUpdate(); err == nil {
+		t.Error("CanUpdate() should return an error when the version is invalid")
+	}
+
+	u = &updater.Updater{
+		Provider:        &provider.Local{Path: solutionDir},
+		ExecutableName:  "test",
+		Version:         "v0.9",
+		VersionComparer: version.NumericComparer{},
+	}
+	if _, err := u.CanUpdate(); err == nil {
+		t.Error("CanUpdate() should return an error when the version does not match the VersionComparer")
+	}
+}
+
+func TestUpdaterVersionComparerProvider(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	solution := filepath.Join(tmpDir, "app-2026.11.zip")
+	if err = writeZipSolution(solution, map[string][]byte{"test": []byte("new")}); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []provider.Provider{&provider.Zip{Path: solution}, &provider.Multi{Providers: []provider.Provider{&provider.Zip{Path: solution}}}} {
+		u := &updater.Updater{
+			Provider:        p,
+			ExecutableName:  "test",
+			Version:         "2026.10",
+			VersionComparer: version.CalverComparer{},
+		}
+		canUpdate, err := u.CanUpdate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !canUpdate {
+			t.Errorf("%T should find the calendar version of %s", p, solution)
+		}
+	}
+}
+
+func TestUpdaterUpdateContextCancelled(t *testing.T) {
+	u := &updater.Updater{
+		Provider:       &provider.Local{Path: filepath.Join("testdata", "testSolution")},
+		ExecutableName: "test",
+		Version:        "v0.1",
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	status, err := u.UpdateContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Error("UpdateContext() should return context.Canceled")
+	}
+	if status != updater.Unknown {
+		t.Error("status should be updater.Unknown")
+	}
+}
+
 // Tests when updater can't find the remote executable
 func TestUpdaterNoRemoteExecutable(t *testing.T) {
 	tmpDir, err := fileio.TempDir()
@@ -286,3 +411,189 @@ func TestPostUpdateFunc(t *testing.T) {
 		t.Error("postUpdateFuncUpdater != u")
 	}
 }
+
+func TestUpdaterProgress(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	executable := filepath.Join(tmpDir, "executable")
+	if err = os.WriteFile(executable, []byte("old executable"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	newExecutable := bytes.Repeat([]byte("new executable"), 100000)
+	solution := filepath.Join(tmpDir, "solution-v1.1.0.zip")
+	if err = writeZipSolution(solution, map[string][]byte{"test": newExecutable}); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []provider.Progress
+	u := &updater.Updater{
+		Provider:           &provider.Zip{Path: solution},
+		ExecutableName:     "test",
+		Version:            "v1.0.0",
+		OverrideExecutable: executable,
+		ProgressFunc: func(progress provider.Progress) {
+			events = append(events, progress)
+		},
+		ProgressInterval: time.Hour,
+	}
+	status, err := u.Update()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != updater.Updated {
+		t.Fatal("status != updater.Updated")
+	}
+	content, err := os.ReadFile(executable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, newExecutable) {
+		t.Error("The executable should be updated")
+	}
+
+	extractEvents := 0
+	for _, event := range events {
+		if event.Stage == provider.StageExtract {
+			extractEvents += 1
+			if event.Total != int64(len(newExecutable)) {
+				t.Errorf("Bad extraction total: %d", event.Total)
+			}
+		}
+	}
+	if extractEvents != 2 {
+		t.Errorf("Only the start and the end of the extraction should be reported, got %d events", extractEvents)
+	}
+	last := events[len(events)-1]
+	if last.Stage != provider.StagePatch || !last.Done() || last.Path != executable {
+		t.Errorf("The last event should be the end of the patch, got %+v", last)
+	}
+}
+
+func TestUpdaterExecutableNameMatching(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	solution := filepath.Join(tmpDir, "solution-v1.1.0.zip")
+	err = writeZipSolution(solution, map[string][]byte{
+		"bin/myapp":                             []byte("myapp"),
+		"bin/myapp-helper":                      []byte("myapp-helper"),
+		"bin/myapp.sha256":                      []byte("checksum"),
+		"bin/myapp_v1.1.0_" + runtime.GOOS:      []byte("myapp templated"),
+		"bin/otherapp_" + runtime.GOOS + ".exe": []byte("otherapp"),
+		"bin/bothapp":                           []byte("bothapp"),
+		"bin/bothapp.exe":                       []byte("bothapp.exe"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bothExpected := "bothapp"
+	if runtime.GOOS == "windows" {
+		bothExpected = "bothapp.exe"
+	}
+
+	tests := []struct {
+		executableName string
+		expected       string
+	}{
+		{"myapp", "myapp"},
+		{"myapp-*", "myapp-helper"},
+		{"myapp_{{.Version}}_{{.GOOS}}", "myapp templated"},
+		{"otherapp_{{.GOOS}}", "otherapp"},
+		{"bothapp", bothExpected},
+	}
+	for _, test := range tests {
+		executable := filepath.Join(tmpDir, "executable")
+		if err = os.WriteFile(executable, []byte("old"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		u := &updater.Updater{
+			Provider:           &provider.Zip{Path: solution},
+			ExecutableName:     test.executableName,
+			Version:            "v1.0.0",
+			OverrideExecutable: executable,
+		}
+		if _, err = u.Update(); err != nil {
+			t.Fatal(err)
+		}
+		content, err := os.ReadFile(executable)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != test.expected {
+			t.Errorf("ExecutableName %s installed %q, expected %q", test.executableName, content, test.expected)
+		}
+		os.Remove(executable + ".old")
+	}
+
+	for _, name := range []string{"myapp*", "doesnotexist"} {
+		executable := filepath.Join(tmpDir, "executable")
+		u := &updater.Updater{
+			Provider:           &provider.Zip{Path: solution},
+			ExecutableName:     name,
+			Version:            "v1.0.0",
+			OverrideExecutable: executable,
+		}
+		status, err := u.Update()
+		if err == nil {
+			t.Errorf("Update() should fail with ExecutableName %s", name)
+		}
+		if status != updater.Unknown {
+			t.Errorf("status should be Unknown with ExecutableName %s", name)
+		}
+	}
+	u := &updater.Updater{
+		Provider:           &provider.Zip{Path: solution},
+		ExecutableName:     "myapp*",
+		Version:            "v1.0.0",
+		OverrideExecutable: filepath.Join(tmpDir, "executable"),
+	}
+	if _, err = u.Update(); !errors.Is(err, provider.ErrAmbiguousName) {
+		t.Errorf("Update() should return ErrAmbiguousName, got %v", err)
+	}
+}
+
+func TestUpdaterRecover(t *testing.T) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	executable := filepath.Join(tmpDir, "executable")
+	u := &updater.Updater{
+		Provider:           &provider.Local{Path: filepath.Join("testdata", "testSolution")},
+		ExecutableName:     "test",
+		Version:            "v1.0",
+		OverrideExecutable: executable,
+	}
+	if err = u.Recover(); err != nil {
+		t.Fatal("Recover() should do nothing when no update was interrupted: ", err)
+	}
+
+	// The executable was moved to its backup and the process was killed
+	if err = os.WriteFile(executable+".old", []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	journal := fmt.Sprintf(`{"state": "started", "destination_path": %q, "backup_path": %q}`,
+		executable, executable+".old")
+	if err = os.WriteFile(executable+".journal", []byte(journal), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = u.Recover(); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(executable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "old" {
+		t.Errorf("Recover() should restore the executable, got %s", content)
+	}
+}
@@ -0,0 +1,76 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ErrValidationFailed is returned when the downloaded executable is not valid
+var ErrValidationFailed = errors.New("executable validation failed")
+
+// DefaultValidationTimeout is the timeout of CommandValidator when none is specified
+const DefaultValidationTimeout = 10 * time.Second
+
+// ValidateFunc is called before installing the downloaded executable
+// candidatePath is the path of the downloaded executable (in a temporary directory)
+// Returning an error aborts the update, the installed executable is not modified
+// ctx is the context of the update: the validation must stop when it is done
+type ValidateFunc func(ctx context.Context, u *Updater, candidatePath string) error
+
+// CommandValidator validates the downloaded executable by running it
+// The validation succeeds if the command exits with status 0 before the timeout
+// and its output contains what is expected
+type CommandValidator struct {
+	Args           []string      // Arguments passed to the executable, example: {"-version"}
+	Timeout        time.Duration // (optional) Maximum duration of the command (DefaultValidationTimeout by default)
+	ExpectedOutput string        // (optional) The output (stdout and stderr) must contain ExpectedOutput
+	ExpectVersion  bool          // (optional) The output must contain the latest version
+}
+
+// Validate runs the executable candidate, the command is killed when ctx is done
+// It can be used as Updater.ValidateFunc:
+//
+//	u.ValidateFunc = (&updater.CommandValidator{Args: []string{"-version"}, ExpectVersion: true}).Validate
+func (v *CommandValidator) Validate(ctx context.Context, u *Updater, candidatePath string) error {
+	timeout := v.Timeout
+	if timeout <= 0 {
+		timeout = DefaultValidationTimeout
+	}
+	// The file might not be executable depending on the provider
+	if err := os.Chmod(candidatePath, 0755); err != nil {
+		return err
+	}
+	parent := ctx
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, candidatePath, v.Args...)
+	cmd.WaitDelay = time.Second // do not wait for the children which are still using the output
+	output, err := cmd.CombinedOutput()
+	if err := parent.Err(); err != nil {
+		return err
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%w: timed out after %s", ErrValidationFailed, timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrValidationFailed, err)
+	}
+	if v.ExpectedOutput != "" && !strings.Contains(string(output), v.ExpectedOutput) {
+		return fmt.Errorf("%w: output does not contain %q", ErrValidationFailed, v.ExpectedOutput)
+	}
+	if v.ExpectVersion {
+		latestVersion, err := u.GetLatestVersionContext(parent)
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(string(output), latestVersion) {
+			return fmt.Errorf("%w: output does not contain version %s", ErrValidationFailed, latestVersion)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package updater_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mouuff/go-rocket-update/internal/fileio"
+	provider "github.com/mouuff/go-rocket-update/pkg/provider"
+	"github.com/mouuff/go-rocket-update/pkg/updater"
+)
+
+// testValidation updates an executable with a shell script and validates it with validator
+func testValidation(t *testing.T, ctx context.Context, script string, validator *updater.CommandValidator) (string, error) {
+	tmpDir, err := fileio.TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	executable := filepath.Join(tmpDir, "executable")
+	if err = os.WriteFile(executable, []byte("old executable"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	solution := filepath.Join(tmpDir, "solution-v1.1.0.zip")
+	if err = writeZipSolution(solution, map[string][]byte{"test": []byte(script)}); err != nil {
+		t.Fatal(err)
+	}
+	u := &updater.Updater{
+		Provider:           &provider.Zip{Path: solution},
+		ExecutableName:     "test",
+		Version:            "v1.0.0",
+		OverrideExecutable: executable,
+		ValidateFunc:       validator.Validate,
+	}
+	_, err = u.UpdateContext(ctx)
+	content, readErr := os.ReadFile(executable)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	if err != nil && fileio.FileExists(executable+".old") {
+		t.Error("The executable should not be touched when the validation fails")
+	}
+	return string(content), err
+}
+
+func TestCommandValidator(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not supported on windows")
+	}
+	script := "#!/bin/sh\necho \"version $1 v1.1.0\"\n"
+	content, err := testValidation(t, context.Background(), script, &updater.CommandValidator{
+		Args:           []string{"-version"},
+		ExpectedOutput: "version -version",
+		ExpectVersion:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content != script {
+		t.Error("The executable should be updated")
+	}
+
+	content, err = testValidation(t, context.Background(), "#!/bin/sh\necho v1.0.0\n", &updater.CommandValidator{ExpectVersion: true})
+	if !errors.Is(err, updater.ErrValidationFailed) {
+		t.Error("The validation should fail when the output does not contain the version")
+	}
+	if content != "old executable" {
+		t.Error("The executable should not be updated")
+	}
+
+	_, err = testValidation(t, context.Background(), "#!/bin/sh\nexit 1\n", &updater.CommandValidator{})
+	if !errors.Is(err, updater.ErrValidationFailed) {
+		t.Error("The validation should fail when the command fails")
+	}
+
+	_, err = testValidation(t, context.Background(), "#!/bin/sh\nsleep 10\n", &updater.CommandValidator{Timeout: 100 * time.Millisecond})
+	if !errors.Is(err, updater.ErrValidationFailed) {
+		t.Error("The validation should fail when the command times out")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	_, err = testValidation(t, ctx, "#!/bin/sh\nsleep 10\n", &updater.CommandValidator{Timeout: 10 * time.Second})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("The validation should be cancelled with the update, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("The validation should stop when the update is cancelled, it took %s", elapsed)
+	}
+}
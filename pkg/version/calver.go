@@ -0,0 +1,73 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// calverRegex finds a calendar version, the version must not follow a digit or a dot
+// The modifier is limited like the prerelease of semverRegex, so platform suffixes are not part of the version
+var calverRegex = regexp.MustCompile(`(?:^|[^0-9.])(v?[0-9]{2,4}\.[0-9]{1,2}(?:\.[0-9]+)*(?:-(?:(?i:alpha|beta|rc|pre|dev)[0-9A-Za-z]*|[0-9][0-9A-Za-z]*)(?:\.[0-9A-Za-z]+)*)?)`)
+
+// CalverComparer compares calendar versions (https://calver.org), example: 2026.10.3
+// Every number is compared in order, missing numbers are considered to be 0.
+// A modifier (example: 2026.10.3-beta) is compared like a semantic version prerelease.
+type CalverComparer struct{}
+
+// parseCalver parses a calendar version into its numbers and its modifier
+func parseCalver(s string) ([]uint64, []string, error) {
+	str := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	var modifier []string
+	if i := strings.Index(str, "-"); i >= 0 {
+		var err error
+		modifier, err = parseIdentifiers(str[i+1:])
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %q: bad modifier", ErrInvalidVersion, s)
+		}
+		str = str[:i]
+	}
+	var numbers []uint64
+	for _, part := range strings.Split(str, ".") {
+		if !isNumeric(part) {
+			return nil, nil, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+		}
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %q: %v", ErrInvalidVersion, s, err)
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, modifier, nil
+}
+
+// Compare compares two calendar versions
+func (CalverComparer) Compare(a, b string) (int, error) {
+	aNumbers, aModifier, err := parseCalver(a)
+	if err != nil {
+		return 0, err
+	}
+	bNumbers, bModifier, err := parseCalver(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < len(aNumbers) || i < len(bNumbers); i++ {
+		var aN, bN uint64
+		if i < len(aNumbers) {
+			aN = aNumbers[i]
+		}
+		if i < len(bNumbers) {
+			bN = bNumbers[i]
+		}
+		if c := compareUint(aN, bN); c != 0 {
+			return c, nil
+		}
+	}
+	return (&Semver{Prerelease: aModifier}).Compare(&Semver{Prerelease: bModifier}), nil
+}
+
+// Find finds the first calendar version (with at least a year and a month) in s
+func (CalverComparer) Find(s string) string {
+	return findSubmatch(calverRegex, s)
+}
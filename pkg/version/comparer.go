@@ -0,0 +1,57 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Comparer describes a versioning scheme
+type Comparer interface {
+	// Compare returns -1 if a is older than b, 0 if they are equal and 1 if a is newer than b
+	Compare(a, b string) (int, error)
+	// Find finds the first version in s (example: a file name), returns an empty string if there is none
+	Find(s string) string
+}
+
+// Default is the Comparer used when none is specified
+var Default Comparer = SemverComparer{}
+
+// OrDefault returns c or Default if c is nil
+func OrDefault(c Comparer) Comparer {
+	if c == nil {
+		return Default
+	}
+	return c
+}
+
+// Latest returns the latest of the given versions
+// versions which can't be parsed by the comparer are ignored
+func Latest(c Comparer, versions []string) (string, error) {
+	c = OrDefault(c)
+	latest := ""
+	for _, v := range versions {
+		if _, err := c.Compare(v, v); err != nil {
+			continue
+		}
+		if latest == "" {
+			latest = v
+			continue
+		}
+		if cmp, _ := c.Compare(v, latest); cmp > 0 {
+			latest = v
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("%w: no valid version found", ErrInvalidVersion)
+	}
+	return latest, nil
+}
+
+// findSubmatch returns the first submatch of re in s
+func findSubmatch(re *regexp.Regexp, s string) string {
+	submatches := re.FindStringSubmatch(s)
+	if len(submatches) < 2 {
+		return ""
+	}
+	return submatches[1]
+}
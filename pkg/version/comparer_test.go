@@ -0,0 +1,87 @@
+package version_test
+
+import (
+	"testing"
+
+	"github.com/mouuff/go-rocket-update/pkg/version"
+)
+
+func TestComparers(t *testing.T) {
+	tests := []struct {
+		comparer version.Comparer
+		a, b     string
+		expected int
+	}{
+		{version.SemverComparer{}, "v1.2.0", "v1.10.0", -1},
+		{version.CalverComparer{}, "2026.10.3", "2026.9.30", 1},
+		{version.CalverComparer{}, "2026.10", "2026.10.0", 0},
+		{version.CalverComparer{}, "v2026.10.3-beta", "2026.10.3", -1},
+		{version.CalverComparer{}, "2026.10.3", "2027.01.1", -1},
+		{version.NumericComparer{}, "99", "100", -1},
+		{version.NumericComparer{}, "v1234", "1234", 0},
+		{version.NumericComparer{}, "1235", "1234", 1},
+	}
+	for _, test := range tests {
+		result, err := test.comparer.Compare(test.a, test.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != test.expected {
+			t.Errorf("%T.Compare(%s, %s) = %d, expected %d", test.comparer, test.a, test.b, result, test.expected)
+		}
+	}
+
+	if _, err := (version.CalverComparer{}).Compare("2026.x", "2026.1"); err == nil {
+		t.Error("CalverComparer should not compare an invalid version")
+	}
+	if _, err := (version.NumericComparer{}).Compare("1.2", "3"); err == nil {
+		t.Error("NumericComparer should not compare an invalid version")
+	}
+}
+
+func TestComparersFind(t *testing.T) {
+	tests := []struct {
+		comparer version.Comparer
+		s        string
+		expected string
+	}{
+		{version.SemverComparer{}, "binaries-v1.4.53", "v1.4.53"},
+		{version.SemverComparer{}, "binaries_linux_amd64-1.0.0-rc.1", "1.0.0-rc.1"},
+		{version.SemverComparer{}, "binaries-v1.4", ""},
+		{version.SemverComparer{}, "myapp-1.2.3-linux", "1.2.3"},
+		{version.SemverComparer{}, "myapp-1.2.3-beta.2-darwin-arm64", "1.2.3-beta.2"},
+		{version.CalverComparer{}, "binaries_amd64-2026.10.3", "2026.10.3"},
+		{version.CalverComparer{}, "binaries-v1.4.53", ""},
+		{version.CalverComparer{}, "app-2026.10.3-linux-amd64", "2026.10.3"},
+		{version.CalverComparer{}, "app-2026.10.3-beta.1-windows", "2026.10.3-beta.1"},
+		{version.NumericComparer{}, "binaries_amd64-1234", "1234"},
+		{version.NumericComparer{}, "build-v42_linux", "v42"},
+		{version.NumericComparer{}, "binaries_amd64-v1.4.53", ""},
+	}
+	for _, test := range tests {
+		result := test.comparer.Find(test.s)
+		if result != test.expected {
+			t.Errorf("%T.Find(%s) = %s, expected %s", test.comparer, test.s, result, test.expected)
+		}
+	}
+}
+
+func TestLatest(t *testing.T) {
+	latest, err := version.Latest(nil, []string{"v1.0.0", "invalid", "v1.2.0-rc.1", "v1.1.9"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest != "v1.2.0-rc.1" {
+		t.Errorf("latest = %s", latest)
+	}
+	latest, err = version.Latest(version.NumericComparer{}, []string{"9", "10", "v2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest != "10" {
+		t.Errorf("latest = %s", latest)
+	}
+	if _, err = version.Latest(nil, []string{"invalid"}); err == nil {
+		t.Error("Latest() should return an error without valid versions")
+	}
+}
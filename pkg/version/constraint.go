@@ -0,0 +1,173 @@
+package version
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidConstraint is returned when a constraint can't be parsed
+var ErrInvalidConstraint = errors.New("invalid version constraint")
+
+// comparison is a single condition of a constraint, example: ">=2.0"
+type comparison struct {
+	operator string
+	version  string
+}
+
+// Constraint restricts the accepted versions, example: ">=2.0 <3.0" or "2.x || 3.1.x"
+// The conditions separated by spaces (or commas) must all be satisfied, "||" separates alternatives.
+// The operators are =, !=, >, >=, < and <=, a version without operator must be equal.
+// Wildcards ("x", "X" or "*") match any number: "2.x" is the same as ">=2.0.0 <3.0.0"
+// "<3.0.0" excludes the prereleases of 3.0.0 (like 3.0.0-rc.1)
+type Constraint struct {
+	str          string
+	alternatives [][]comparison
+}
+
+// operators ordered so that the longest operators are tried first
+var operators = []string{">=", "<=", "!=", "==", ">", "<", "="}
+
+// ParseConstraint parses a constraint (see Constraint)
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{str: s}
+	for _, alternative := range strings.Split(s, "||") {
+		fields := strings.Fields(strings.ReplaceAll(alternative, ",", " "))
+		var comparisons []comparison
+		for i := 0; i < len(fields); i++ {
+			field := fields[i]
+			operator := ""
+			for _, op := range operators {
+				if strings.HasPrefix(field, op) {
+					operator = op
+					break
+				}
+			}
+			v := strings.TrimPrefix(field, operator)
+			if v == "" && i+1 < len(fields) {
+				// operator separated from the version: ">= 2.0"
+				i++
+				v = fields[i]
+			}
+			if v == "" {
+				return nil, fmt.Errorf("%w: %q: missing version", ErrInvalidConstraint, s)
+			}
+			expanded, err := expandComparison(operator, v)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q: %v", ErrInvalidConstraint, s, err)
+			}
+			comparisons = append(comparisons, expanded...)
+		}
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("%w: %q: empty alternative", ErrInvalidConstraint, s)
+		}
+		c.alternatives = append(c.alternatives, comparisons)
+	}
+	return c, nil
+}
+
+// isWildcard checks if part of a version is a wildcard
+func isWildcard(part string) bool {
+	return part == "x" || part == "X" || part == "*"
+}
+
+// expandComparison turns the wildcards into a range: "2.x" gives ">=2.0.0" and "<3.0.0"
+func expandComparison(operator string, v string) ([]comparison, error) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimPrefix(v, "v"), "V"), ".")
+	wildcard := -1
+	for i, part := range parts {
+		if isWildcard(part) {
+			wildcard = i
+			break
+		}
+	}
+	if wildcard < 0 {
+		if operator == "" || operator == "==" {
+			operator = "="
+		}
+		return []comparison{{operator: operator, version: v}}, nil
+	}
+	if operator != "" && operator != "=" && operator != "==" {
+		return nil, fmt.Errorf("wildcards can't be used with %s", operator)
+	}
+	for _, part := range parts[wildcard:] {
+		if !isWildcard(part) {
+			return nil, fmt.Errorf("%s: wildcards must be at the end", v)
+		}
+	}
+	if wildcard == 0 {
+		return nil, nil // matches any version
+	}
+	lower := make([]string, 3)
+	upper := make([]string, 3)
+	for i := range lower {
+		lower[i], upper[i] = "0", "0"
+		if i < wildcard {
+			lower[i], upper[i] = parts[i], parts[i]
+		}
+	}
+	last, err := strconv.ParseUint(parts[wildcard-1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", v, err)
+	}
+	upper[wildcard-1] = strconv.FormatUint(last+1, 10)
+	return []comparison{
+		{operator: ">=", version: strings.Join(lower, ".")},
+		{operator: "<", version: strings.Join(upper, ".")},
+	}, nil
+}
+
+// isPrereleaseOf checks if v is a prerelease of the release bound, example: "3.0.0-rc.1" of "3.0.0"
+// "<3.0.0" must not accept the prereleases of 3.0.0 even if they are older
+func isPrereleaseOf(v string, bound string) bool {
+	sv, err := ParseSemver(v)
+	if err != nil || len(sv.Prerelease) == 0 {
+		return false
+	}
+	sb, err := ParseSemver(bound)
+	if err != nil || len(sb.Prerelease) > 0 {
+		return false
+	}
+	return sv.Major == sb.Major && sv.Minor == sb.Minor && sv.Patch == sb.Patch
+}
+
+// Check checks if v satisfies the constraint, versions are compared using c (Default if nil)
+func (c *Constraint) Check(cmp Comparer, v string) (bool, error) {
+	cmp = OrDefault(cmp)
+	for _, alternative := range c.alternatives {
+		satisfied := true
+		for _, comparison := range alternative {
+			result, err := cmp.Compare(v, comparison.version)
+			if err != nil {
+				return false, err
+			}
+			switch comparison.operator {
+			case "=":
+				satisfied = result == 0
+			case "!=":
+				satisfied = result != 0
+			case ">":
+				satisfied = result > 0
+			case ">=":
+				satisfied = result >= 0
+			case "<":
+				satisfied = result < 0 && !isPrereleaseOf(v, comparison.version)
+			case "<=":
+				satisfied = result <= 0
+			}
+			if !satisfied {
+				break
+			}
+		}
+		if satisfied {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// String returns the constraint as it was parsed
+func (c *Constraint) String() string {
+	return c.str
+}
@@ -0,0 +1,65 @@
+package version_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mouuff/go-rocket-update/pkg/version"
+)
+
+func TestConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{">=2.0 <3.0", "v2.3.1", true},
+		{">=2.0 <3.0", "v3.0.0", false},
+		{">=2.0 <3.0", "v1.9.9", false},
+		{">= 2.0, < 3.0", "v2.0.0", true},
+		{"2.x", "v2.9.0", true},
+		{"2.x", "v3.0.0", false},
+		{"2.x", "v3.0.0-alpha.1", false},
+		{">=2.0 <3.0", "v3.0.0-rc.1", false},
+		{">=2.0 <3.0", "v2.5.0-rc.1", true},
+		{"<3.0.0-rc.2", "v3.0.0-rc.1", true},
+		{"v2.3.x", "v2.3.7", true},
+		{"2.3.*", "v2.4.0", false},
+		{"*", "v9.0.0", true},
+		{"2.3.1", "v2.3.1", true},
+		{"=2.3.1", "v2.3.2", false},
+		{"!=2.3.1", "v2.3.1", false},
+		{"<=2.3.1", "v2.3.1", true},
+		{">2.3.1", "v2.3.1", false},
+		{"1.x || >=3.0", "v1.2.0", true},
+		{"1.x || >=3.0", "v2.2.0", false},
+		{"1.x || >=3.0", "v3.2.0", true},
+	}
+	for _, test := range tests {
+		c, err := version.ParseConstraint(test.constraint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := c.Check(nil, test.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != test.expected {
+			t.Errorf("ParseConstraint(%q).Check(%s) = %v, expected %v", test.constraint, test.version, result, test.expected)
+		}
+	}
+
+	for _, bad := range []string{"", ">=", "1.x ||", ">=2.x", "2.x.1"} {
+		if _, err := version.ParseConstraint(bad); !errors.Is(err, version.ErrInvalidConstraint) {
+			t.Errorf("ParseConstraint(%q) should return ErrInvalidConstraint", bad)
+		}
+	}
+
+	c, err := version.ParseConstraint(">=2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.Check(nil, "latest"); err == nil {
+		t.Error("Check should fail with an invalid version")
+	}
+}
@@ -0,0 +1,46 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// numericRegex finds a number which is not part of a word or of a dotted version
+var numericRegex = regexp.MustCompile(`(?:^|[^0-9A-Za-z.])(v?[0-9]+)(?:$|[^0-9A-Za-z.])`)
+
+// NumericComparer compares monotonically increasing build numbers, example: 1234
+// A leading "v" is accepted.
+type NumericComparer struct{}
+
+// parseNumeric parses a build number
+func parseNumeric(s string) (uint64, error) {
+	str := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if !isNumeric(str) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+	}
+	n, err := strconv.ParseUint(str, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q: %v", ErrInvalidVersion, s, err)
+	}
+	return n, nil
+}
+
+// Compare compares two build numbers
+func (NumericComparer) Compare(a, b string) (int, error) {
+	aN, err := parseNumeric(a)
+	if err != nil {
+		return 0, err
+	}
+	bN, err := parseNumeric(b)
+	if err != nil {
+		return 0, err
+	}
+	return compareUint(aN, bN), nil
+}
+
+// Find finds the first build number in s, example: "app-1234_linux" gives "1234"
+func (NumericComparer) Find(s string) string {
+	return findSubmatch(numericRegex, s)
+}
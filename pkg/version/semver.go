@@ -0,0 +1,196 @@
+// Package version parses and orders the versions returned by the providers
+package version
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidVersion is returned when a version can't be parsed
+var ErrInvalidVersion = errors.New("invalid version")
+
+// Semver describes a semantic version (https://semver.org)
+// A leading "v" is optional and missing minor or patch numbers are considered to be 0,
+// so "v1.2" is the same as "1.2.0"
+type Semver struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease []string // Prerelease identifiers, example: "1.0.0-rc.1" gives {"rc", "1"}
+	Build      []string // Build metadata, it is ignored when comparing versions
+}
+
+// ParseSemver parses a semantic version
+func ParseSemver(s string) (*Semver, error) {
+	str := strings.TrimSpace(s)
+	str = strings.TrimPrefix(strings.TrimPrefix(str, "v"), "V")
+	v := &Semver{}
+
+	if i := strings.Index(str, "+"); i >= 0 {
+		build, err := parseIdentifiers(str[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: bad build metadata", ErrInvalidVersion, s)
+		}
+		v.Build = build
+		str = str[:i]
+	}
+	if i := strings.Index(str, "-"); i >= 0 {
+		prerelease, err := parseIdentifiers(str[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: bad prerelease", ErrInvalidVersion, s)
+		}
+		v.Prerelease = prerelease
+		str = str[:i]
+	}
+
+	parts := strings.Split(str, ".")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("%w: %q: too many numbers", ErrInvalidVersion, s)
+	}
+	numbers := []*uint64{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		if !isNumeric(part) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+		}
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidVersion, s, err)
+		}
+		*numbers[i] = n
+	}
+	return v, nil
+}
+
+// parseIdentifiers parses dot separated identifiers made of [0-9A-Za-z-]
+func parseIdentifiers(s string) ([]string, error) {
+	identifiers := strings.Split(s, ".")
+	for _, identifier := range identifiers {
+		if identifier == "" {
+			return nil, ErrInvalidVersion
+		}
+		for _, r := range identifier {
+			if !(r >= '0' && r <= '9') && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && r != '-' {
+				return nil, ErrInvalidVersion
+			}
+		}
+	}
+	return identifiers, nil
+}
+
+// isNumeric checks if s is only made of digits
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// compareUint compares two numbers
+func compareUint(a, b uint64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+// compareIdentifier compares two prerelease identifiers
+// numeric identifiers are compared numerically and always have a lower precedence
+// than alphanumeric identifiers
+func compareIdentifier(a, b string) int {
+	aNumeric, bNumeric := isNumeric(a), isNumeric(b)
+	if aNumeric && bNumeric {
+		aN, errA := strconv.ParseUint(a, 10, 64)
+		bN, errB := strconv.ParseUint(b, 10, 64)
+		if errA == nil && errB == nil {
+			return compareUint(aN, bN)
+		}
+	} else if aNumeric {
+		return -1
+	} else if bNumeric {
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// Compare compares v to o
+// returns -1 if v is older than o, 0 if they are equal and 1 if v is newer than o
+func (v *Semver) Compare(o *Semver) int {
+	if c := compareUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	// A version without prerelease is newer than the same version with a prerelease
+	if len(v.Prerelease) == 0 && len(o.Prerelease) == 0 {
+		return 0
+	} else if len(v.Prerelease) == 0 {
+		return 1
+	} else if len(o.Prerelease) == 0 {
+		return -1
+	}
+	for i := 0; i < len(v.Prerelease) && i < len(o.Prerelease); i++ {
+		if c := compareIdentifier(v.Prerelease[i], o.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareUint(uint64(len(v.Prerelease)), uint64(len(o.Prerelease)))
+}
+
+// String formats the version without the leading "v"
+func (v *Semver) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// CompareSemver parses and compares two semantic versions
+// returns -1 if a is older than b, 0 if they are equal and 1 if a is newer than b
+func CompareSemver(a, b string) (int, error) {
+	va, err := ParseSemver(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := ParseSemver(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.Compare(vb), nil
+}
+
+// semverRegex finds a semantic version, the version must not follow a digit or a dot
+// The prerelease must start with alpha, beta, rc, pre, dev or a digit and stops at the next dash,
+// so platform suffixes are not part of the version (example: binaries-v1.0.0-linux-amd64 is "v1.0.0")
+var semverRegex = regexp.MustCompile(`(?:^|[^0-9.])(v?[0-9]+\.[0-9]+\.[0-9]+(?:-(?:(?i:alpha|beta|rc|pre|dev)[0-9A-Za-z]*|[0-9][0-9A-Za-z]*)(?:\.[0-9A-Za-z]+)*)?(?:\+[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?)`)
+
+// SemverComparer compares semantic versions, example: v1.4.2-rc.1
+type SemverComparer struct{}
+
+// Compare compares two semantic versions
+func (SemverComparer) Compare(a, b string) (int, error) {
+	return CompareSemver(a, b)
+}
+
+// Find finds the first semantic version (with major, minor and patch numbers) in s
+func (SemverComparer) Find(s string) string {
+	return findSubmatch(semverRegex, s)
+}
@@ -0,0 +1,83 @@
+package version_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mouuff/go-rocket-update/pkg/version"
+)
+
+func TestParseSemver(t *testing.T) {
+	v, err := version.ParseSemver("v1.2.3-rc.1+build.42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Major != 1 || v.Minor != 2 || v.Patch != 3 {
+		t.Errorf("bad version numbers: %d.%d.%d", v.Major, v.Minor, v.Patch)
+	}
+	if len(v.Prerelease) != 2 || v.Prerelease[0] != "rc" || v.Prerelease[1] != "1" {
+		t.Errorf("bad prerelease: %v", v.Prerelease)
+	}
+	if len(v.Build) != 2 || v.Build[0] != "build" || v.Build[1] != "42" {
+		t.Errorf("bad build: %v", v.Build)
+	}
+	if v.String() != "1.2.3-rc.1+build.42" {
+		t.Errorf("v.String() = %s", v.String())
+	}
+
+	v, err = version.ParseSemver("1.4\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.String() != "1.4.0" {
+		t.Errorf("v.String() = %s", v.String())
+	}
+
+	for _, bad := range []string{"", "v", "1.2.3.4", "1.x", "1.2.3-", "1.2.3-rc..1", "1.2.3+", "v1.2.3-r_c"} {
+		_, err = version.ParseSemver(bad)
+		if !errors.Is(err, version.ErrInvalidVersion) {
+			t.Errorf("ParseSemver(%q) should return ErrInvalidVersion", bad)
+		}
+	}
+}
+
+func TestCompareSemver(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"v1.0.0", "1.0.0", 0},
+		{"v1.0", "v1.0.0", 0},
+		{"v1.0.0+build.1", "v1.0.0+build.2", 0},
+		{"v1.0.1", "v1.0.0", 1},
+		{"v1.10.0", "v1.9.0", 1},
+		{"v2.0.0", "v1.99.99", 1},
+		{"v1.0.0-alpha", "v1.0.0", -1},
+		{"v1.0.0-alpha", "v1.0.0-alpha.1", -1},
+		{"v1.0.0-alpha.1", "v1.0.0-alpha.beta", -1},
+		{"v1.0.0-alpha.beta", "v1.0.0-beta", -1},
+		{"v1.0.0-beta.2", "v1.0.0-beta.11", -1},
+		{"v1.0.0-beta.11", "v1.0.0-rc.1", -1},
+		{"v1.0.0-rc.1", "v1.0.0", -1},
+	}
+	for _, test := range tests {
+		result, err := version.CompareSemver(test.a, test.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != test.expected {
+			t.Errorf("CompareSemver(%s, %s) = %d, expected %d", test.a, test.b, result, test.expected)
+		}
+		result, err = version.CompareSemver(test.b, test.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != -test.expected {
+			t.Errorf("CompareSemver(%s, %s) = %d, expected %d", test.b, test.a, result, -test.expected)
+		}
+	}
+
+	if _, err := version.CompareSemver("v1.0.0", "latest"); err == nil {
+		t.Error("Should not compare an invalid version")
+	}
+}